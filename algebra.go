@@ -0,0 +1,255 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+)
+
+// PathWeight is the accumulated weight of a path under a PathAlgebra.
+type PathWeight interface{}
+
+// EdgeMetric gets a value of the edge between two vertices, e.g. its capacity or probability.
+type EdgeMetric func(from, to ID) float64
+
+// PathAlgebra defines how the weights of paths are built and compared.
+// Dijkstra-style algorithms are correct only if extending a path never makes it better.
+// https://en.wikipedia.org/wiki/Semiring
+type PathAlgebra interface {
+	// Identity gets the weight of the empty path.
+	Identity() PathWeight
+	// Zero gets the weight of an unreachable vertex.
+	Zero() PathWeight
+	// Extend gets the weight of the path extended by the edge from vertex from to vertex to.
+	// An error is returned if the edge is not allowed by the algebra.
+	Extend(path PathWeight, from, to ID, weight float64) (PathWeight, error)
+	// Combine gets the preferred one of the two weights.
+	Combine(a, b PathWeight) PathWeight
+	// Better reports whether weight a is strictly preferred to weight b.
+	Better(a, b PathWeight) bool
+}
+
+// scalarAlgebra is implemented by the algebras whose weights can be ordered by a float64 key.
+// The smaller the key, the better the weight.
+type scalarAlgebra interface {
+	PathAlgebra
+	key(weight PathWeight) float64
+}
+
+// Shortest gets the algebra which sums the edge metrics and prefers the smaller, the metric is the edge weight if nil.
+// Weights of this algebra are float64.
+func Shortest(metric EdgeMetric) PathAlgebra {
+	return &shortestAlgebra{metric}
+}
+
+// Widest gets the algebra which takes the minimum of the edge metrics and prefers the larger, the metric is the edge weight if nil.
+// Weights of this algebra are float64.
+func Widest(metric EdgeMetric) PathAlgebra {
+	return &widestAlgebra{metric}
+}
+
+// MostReliable gets the algebra which multiplies the edge metrics and prefers the larger, the metric is the edge weight if nil.
+// The metrics are probabilities in the range [0, 1]. Weights of this algebra are float64.
+func MostReliable(metric EdgeMetric) PathAlgebra {
+	return &reliableAlgebra{metric}
+}
+
+// Lexicographic gets the algebra which compares the weights of the input algebras one after another.
+// Weights of this algebra are []PathWeight with one weight per input algebra.
+// Note the combination is only correct for Dijkstra-style algorithms if each input algebra keeps its order when extended,
+// e.g. shortest-widest paths can not be calculated in this way while widest-shortest paths can.
+func Lexicographic(algebras ...PathAlgebra) PathAlgebra {
+	return &lexicographicAlgebra{algebras}
+}
+
+type shortestAlgebra struct {
+	metric EdgeMetric
+}
+
+func (algebra *shortestAlgebra) Identity() PathWeight {
+	return float64(0)
+}
+
+func (algebra *shortestAlgebra) Zero() PathWeight {
+	return math.Inf(1)
+}
+
+func (algebra *shortestAlgebra) Extend(path PathWeight, from, to ID, weight float64) (PathWeight, error) {
+	if algebra.metric != nil {
+		weight = algebra.metric(from, to)
+	}
+	if weight < 0 {
+		return nil, &EdgeError{ErrNegativeWeight, from, to}
+	}
+
+	return path.(float64) + weight, nil
+}
+
+func (algebra *shortestAlgebra) Combine(a, b PathWeight) PathWeight {
+	return math.Min(a.(float64), b.(float64))
+}
+
+func (algebra *shortestAlgebra) Better(a, b PathWeight) bool {
+	return a.(float64) < b.(float64)
+}
+
+func (algebra *shortestAlgebra) key(weight PathWeight) float64 {
+	return weight.(float64)
+}
+
+type widestAlgebra struct {
+	metric EdgeMetric
+}
+
+func (algebra *widestAlgebra) Identity() PathWeight {
+	return math.Inf(1)
+}
+
+func (algebra *widestAlgebra) Zero() PathWeight {
+	return float64(0)
+}
+
+func (algebra *widestAlgebra) Extend(path PathWeight, from, to ID, weight float64) (PathWeight, error) {
+	if algebra.metric != nil {
+		weight = algebra.metric(from, to)
+	}
+	if weight < 0 {
+		return nil, &EdgeError{ErrNegativeWeight, from, to}
+	}
+
+	return math.Min(path.(float64), weight), nil
+}
+
+func (algebra *widestAlgebra) Combine(a, b PathWeight) PathWeight {
+	return math.Max(a.(float64), b.(float64))
+}
+
+func (algebra *widestAlgebra) Better(a, b PathWeight) bool {
+	return a.(float64) > b.(float64)
+}
+
+// key maps a width to the key in the min heap, the wider the smaller.
+// -Inf is reserved by the heap, so the unbounded width is mapped to the smallest finite key.
+func (algebra *widestAlgebra) key(weight PathWeight) float64 {
+	if math.IsInf(weight.(float64), 1) {
+		return -math.MaxFloat64
+	}
+
+	return -weight.(float64)
+}
+
+type reliableAlgebra struct {
+	metric EdgeMetric
+}
+
+func (algebra *reliableAlgebra) Identity() PathWeight {
+	return float64(1)
+}
+
+func (algebra *reliableAlgebra) Zero() PathWeight {
+	return float64(0)
+}
+
+func (algebra *reliableAlgebra) Extend(path PathWeight, from, to ID, weight float64) (PathWeight, error) {
+	if algebra.metric != nil {
+		weight = algebra.metric(from, to)
+	}
+	if weight < 0 || weight > 1 {
+		return nil, &EdgeError{ErrWeightOutOfRange, from, to}
+	}
+
+	return path.(float64) * weight, nil
+}
+
+func (algebra *reliableAlgebra) Combine(a, b PathWeight) PathWeight {
+	return math.Max(a.(float64), b.(float64))
+}
+
+func (algebra *reliableAlgebra) Better(a, b PathWeight) bool {
+	return a.(float64) > b.(float64)
+}
+
+func (algebra *reliableAlgebra) key(weight PathWeight) float64 {
+	return -weight.(float64)
+}
+
+type lexicographicAlgebra struct {
+	algebras []PathAlgebra
+}
+
+func (algebra *lexicographicAlgebra) Identity() PathWeight {
+	weight := make([]PathWeight, len(algebra.algebras))
+	for i, each := range algebra.algebras {
+		weight[i] = each.Identity()
+	}
+
+	return weight
+}
+
+func (algebra *lexicographicAlgebra) Zero() PathWeight {
+	weight := make([]PathWeight, len(algebra.algebras))
+	for i, each := range algebra.algebras {
+		weight[i] = each.Zero()
+	}
+
+	return weight
+}
+
+func (algebra *lexicographicAlgebra) Extend(path PathWeight, from, to ID, weight float64) (PathWeight, error) {
+	var err error
+	extended := make([]PathWeight, len(algebra.algebras))
+	for i, each := range algebra.algebras {
+		if extended[i], err = each.Extend(path.([]PathWeight)[i], from, to, weight); err != nil {
+			return nil, err
+		}
+	}
+
+	return extended, nil
+}
+
+func (algebra *lexicographicAlgebra) Combine(a, b PathWeight) PathWeight {
+	if algebra.Better(b, a) {
+		return b
+	}
+
+	return a
+}
+
+func (algebra *lexicographicAlgebra) Better(a, b PathWeight) bool {
+	for i, each := range algebra.algebras {
+		if each.Better(a.([]PathWeight)[i], b.([]PathWeight)[i]) {
+			return true
+		}
+		if each.Better(b.([]PathWeight)[i], a.([]PathWeight)[i]) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// GetPathWeightWith gets the weight along the path by input ids under the algebra.
+// It will get an error if the path is empty or contains vertices not connected.
+func (graph *Graph) GetPathWeightWith(path []ID, algebra PathAlgebra) (PathWeight, error) {
+	if len(path) == 0 {
+		return nil, ErrEmptyPath
+	}
+
+	if _, exists := graph.vertices[path[0]]; !exists {
+		return nil, &VertexError{ErrVertexNotFound, path[0]}
+	}
+
+	var err error
+	weight := algebra.Identity()
+	for i := 0; i < len(path)-1; i++ {
+		edge, exists := graph.egress[path[i]][path[i+1]]
+		if !exists {
+			return nil, &EdgeError{ErrEdgeNotFound, path[i], path[i+1]}
+		}
+		if weight, err = algebra.Extend(weight, path[i], path[i+1], edge.getWeight()); err != nil {
+			return nil, err
+		}
+	}
+
+	return weight, nil
+}
@@ -0,0 +1,156 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of PathAlgebra", func() {
+	var (
+		graph *Graph
+	)
+
+	hop := func(from, to ID) float64 {
+		return 1
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "A", 0.5, nil)
+			graph.AddEdge("A", "T", 2, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph without vertex X, when call dijkstra with algebra api with X, then get two nil and error", func() {
+			dist, prev, err := graph.DijkstraWith("X", Shortest(nil))
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph with edge not allowed by the algebra, when call dijkstra and yen with algebra api, then get error", func() {
+			dist, prev, err := graph.DijkstraWith("S", MostReliable(nil))
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+
+			dist, prev, err = graph.DijkstraWith("S", Lexicographic(Shortest(hop), MostReliable(nil)))
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+
+			weights, paths, err := graph.YenWith("S", "T", 2, MostReliable(nil))
+			Expect(weights).Should(BeNil())
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph, when get the weight of invalid path with algebra, then get error", func() {
+			_, err := graph.GetPathWeightWith(nil, Shortest(nil))
+			Expect(err).Should(HaveOccurred())
+			_, err = graph.GetPathWeightWith([]ID{"X", "S"}, Shortest(nil))
+			Expect(err).Should(HaveOccurred())
+			_, err = graph.GetPathWeightWith([]ID{"S", "T"}, Shortest(nil))
+			Expect(err).Should(HaveOccurred())
+			_, err = graph.GetPathWeightWith([]ID{"S", "A", "T"}, MostReliable(nil))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("B", nil)
+			graph.AddVertex("C", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "A", 0.5, nil)
+			graph.AddEdge("A", "T", 0.5, nil)
+			graph.AddEdge("S", "B", 0.9, nil)
+			graph.AddEdge("B", "C", 0.9, nil)
+			graph.AddEdge("C", "T", 1, nil)
+			graph.AddEdge("S", "T", 0.125, nil)
+			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call dijkstra with shortest algebra, then get the same result as dijkstra api.", func() {
+			expectedDist, expectedPrev, _ := graph.Dijkstra("S")
+			dist, prev, err := graph.DijkstraWith("S", Shortest(nil))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(toFloatWeights(dist)).Should(BeEquivalentTo(expectedDist))
+			Expect(prev).Should(BeEquivalentTo(expectedPrev))
+		})
+
+		It("Given a graph, when call dijkstra with most reliable algebra, then get the paths with the largest product of probabilities.", func() {
+			dist, prev, err := graph.DijkstraWith("S", MostReliable(nil))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["S"]).Should(BeEquivalentTo(1))
+			Expect(dist["T"]).Should(BeNumerically("~", 0.81))
+			Expect(getPath(prev, "T")).Should(BeEquivalentTo([]ID{"S", "B", "C", "T"}))
+		})
+
+		It("Given a graph, when call dijkstra with widest algebra, then get the paths with the largest bottleneck.", func() {
+			dist, prev, err := graph.DijkstraWith("S", Widest(nil))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["S"]).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(dist["T"]).Should(BeEquivalentTo(0.9))
+			Expect(getPath(prev, "T")).Should(BeEquivalentTo([]ID{"S", "B", "C", "T"}))
+		})
+
+		It("Given a graph with equal shortest paths, when call dijkstra with lexicographic algebra, then get the one with the fewest hops.", func() {
+			graph.UpdateEdgeWeight("S", "T", 10)
+			graph.UpdateEdgeWeight("S", "A", 1)
+			graph.UpdateEdgeWeight("A", "T", 1)
+			graph.UpdateEdgeWeight("S", "B", 1)
+			graph.UpdateEdgeWeight("B", "C", 0)
+			graph.UpdateEdgeWeight("C", "T", 1)
+
+			dist, prev, err := graph.DijkstraWith("S", Lexicographic(Shortest(nil), Shortest(hop)))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["T"]).Should(BeEquivalentTo([]PathWeight{float64(2), float64(2)}))
+			Expect(getPath(prev, "T")).Should(BeEquivalentTo([]ID{"S", "A", "T"}))
+
+			dist, prev, err = graph.DijkstraWith("S", Lexicographic(Shortest(hop), Shortest(nil)))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["T"]).Should(BeEquivalentTo([]PathWeight{float64(1), float64(10)}))
+			Expect(getPath(prev, "T")).Should(BeEquivalentTo([]ID{"S", "T"}))
+		})
+
+		It("Given a graph, when call yen with most reliable algebra, then get the top k most reliable paths.", func() {
+			weights, paths, err := graph.YenWith("S", "T", 4, MostReliable(nil))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(weights[0]).Should(BeNumerically("~", 0.81))
+			Expect(paths[0]).Should(BeEquivalentTo([]ID{"S", "B", "C", "T"}))
+			Expect(weights[1]).Should(BeEquivalentTo(0.25))
+			Expect(paths[1]).Should(BeEquivalentTo([]ID{"S", "A", "T"}))
+			Expect(weights[2]).Should(BeEquivalentTo(0.125))
+			Expect(paths[2]).Should(BeEquivalentTo([]ID{"S", "T"}))
+			Expect(weights[3]).Should(BeEquivalentTo(0))
+			Expect(paths[3]).Should(BeNil())
+		})
+
+		It("Given two weights, when combine them with an algebra, then get the preferred one.", func() {
+			Expect(Shortest(nil).Combine(float64(1), float64(2))).Should(BeEquivalentTo(1))
+			Expect(Widest(nil).Combine(float64(1), float64(2))).Should(BeEquivalentTo(2))
+			Expect(MostReliable(nil).Combine(float64(0.1), float64(0.2))).Should(BeEquivalentTo(0.2))
+			lexicographic := Lexicographic(Shortest(nil), Widest(nil))
+			Expect(lexicographic.Combine([]PathWeight{float64(1), float64(1)}, []PathWeight{float64(1), float64(2)})).Should(BeEquivalentTo([]PathWeight{float64(1), float64(2)}))
+			Expect(lexicographic.Combine([]PathWeight{float64(1), float64(1)}, []PathWeight{float64(2), float64(2)})).Should(BeEquivalentTo([]PathWeight{float64(1), float64(1)}))
+		})
+	})
+})
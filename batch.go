@@ -0,0 +1,291 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+)
+
+// Tx is a batch of changes of the graph, which are rolled back together if the batch fails.
+// The changes are seen by the graph and its observers as soon as they are made, and rolled back by the reverse changes.
+type Tx struct {
+	graph *Graph
+	// changes are the changes made through the transaction in order.
+	changes []change
+}
+
+// change is a change of the graph, which can be undone and redone again.
+type change struct {
+	undo func()
+	redo func()
+}
+
+// Batch calls the function with a transaction of the graph.
+// If the function gets an error or panics, all the changes made through the transaction are rolled back in the reverse order,
+// and the error is returned or the panic goes on. Otherwise the changes are recorded as one entry of the journal if it is enabled.
+// The changes made directly through the graph inside the function are not part of the batch.
+func (graph *Graph) Batch(fn func(tx *Tx) error) (err error) {
+	tx := &Tx{graph: graph}
+	sequence := graph.sequence
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+			graph.sequence = sequence
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	committed = true
+
+	if len(tx.changes) != 0 {
+		graph.version++
+		if graph.journal != nil {
+			graph.journal.record(tx.changes)
+		}
+	}
+
+	return nil
+}
+
+// AddVertex adds a new vertex into the graph as the AddVertex of the graph does.
+func (tx *Tx) AddVertex(id ID, v interface{}) error {
+	graph := tx.graph
+	restore := graph.restoreMaps(id)
+	if err := graph.addVertex(id, v); err != nil {
+		return err
+	}
+
+	added := graph.vertices[id]
+	tx.record(func() {
+		graph.deleteVertex(id)
+		restore()
+	}, func() {
+		graph.restoreVertex(id, added, nil, nil)
+	})
+	return nil
+}
+
+// AddEdge adds a new edge between the vertices as the AddEdge of the graph does.
+func (tx *Tx) AddEdge(from ID, to ID, weight float64, e interface{}) error {
+	graph := tx.graph
+	if err := graph.addEdge(from, to, weight, e); err != nil {
+		return err
+	}
+
+	added := graph.egress[from][to]
+	tx.record(func() {
+		graph.deleteEdge(from, to)
+	}, func() {
+		graph.restoreEdge(from, to, added)
+	})
+	return nil
+}
+
+// UpdateEdgeWeight updates the weight of the edge between the vertices as the UpdateEdgeWeight of the graph does.
+func (tx *Tx) UpdateEdgeWeight(from ID, to ID, weight float64) error {
+	graph := tx.graph
+	old, _ := graph.GetEdgeWeight(from, to)
+	if err := graph.updateEdgeWeight(from, to, weight); err != nil {
+		return err
+	}
+
+	tx.record(func() {
+		graph.updateEdgeWeight(from, to, old)
+	}, func() {
+		graph.updateEdgeWeight(from, to, weight)
+	})
+	return nil
+}
+
+// DeleteVertex deletes a vertex from the graph as the DeleteVertex of the graph does.
+func (tx *Tx) DeleteVertex(id ID) interface{} {
+	graph := tx.graph
+	if _, exists := graph.vertices[id]; !exists {
+		return nil
+	}
+	// the edges are copied, since they are deleted from the maps of the vertex one by one.
+	egress := make(map[ID]*edge, len(graph.egress[id]))
+	for to, each := range graph.egress[id] {
+		egress[to] = each
+	}
+	ingress := make(map[ID]*edge, len(graph.ingress[id]))
+	for from, each := range graph.ingress[id] {
+		ingress[from] = each
+	}
+	deleted := graph.deleteVertex(id)
+
+	tx.record(func() {
+		graph.restoreVertex(id, deleted, egress, ingress)
+	}, func() {
+		graph.deleteVertex(id)
+	})
+	return deleted.self
+}
+
+// DeleteEdge deletes the edge between the vertices as the DeleteEdge of the graph does.
+func (tx *Tx) DeleteEdge(from ID, to ID) interface{} {
+	graph := tx.graph
+	if _, exists := graph.vertices[from]; !exists {
+		return nil
+	}
+	if _, exists := graph.vertices[to]; !exists {
+		return nil
+	}
+	deleted := graph.deleteEdge(from, to)
+	if deleted == nil {
+		return nil
+	}
+
+	tx.record(func() {
+		graph.restoreEdge(from, to, deleted)
+	}, func() {
+		graph.deleteEdge(from, to)
+	})
+	return deleted.self
+}
+
+// AddVertexWithEdges adds a vertex together with its edges as the AddVertexWithEdges of the graph does.
+// Nothing is added if any edge is invalid.
+func (tx *Tx) AddVertexWithEdges(v Vertex) error {
+	graph := tx.graph
+	id := v.ID()
+	if _, exists := graph.vertices[id]; exists {
+		return &VertexError{ErrDuplicateVertex, id}
+	}
+	edges := v.Edges()
+	for _, each := range edges {
+		from, to, weight := each.Get()
+		if weight == math.Inf(-1) {
+			return &EdgeError{ErrReservedWeight, from, to}
+		}
+		if from != id && to != id {
+			return &EdgeError{ErrUnrelatedEdge, from, to}
+		}
+	}
+
+	restore := graph.restoreMaps(id)
+	graph.sequence++
+	added := &vertex{v, true, graph.sequence}
+	add := func() {
+		graph.vertices[id] = added
+		graph.egress[id] = make(map[ID]*edge)
+		graph.ingress[id] = make(map[ID]*edge)
+		graph.invalidate()
+		for _, each := range graph.listeners {
+			each.vertexAdded(id)
+		}
+	}
+	add()
+	tx.record(func() {
+		delete(graph.vertices, id)
+		restore()
+		graph.invalidate()
+		for _, each := range graph.listeners {
+			each.vertexDeleted(id)
+		}
+	}, add)
+
+	for _, eachEdge := range edges {
+		from, to, weight := eachEdge.Get()
+		// the edges from or to an unknown vertex are kept in the maps created for it, which are deleted together on rollback.
+		for _, end := range []ID{from, to} {
+			if _, exists := graph.egress[end]; !exists {
+				end := end
+				create := func() {
+					graph.egress[end] = make(map[ID]*edge)
+					graph.ingress[end] = make(map[ID]*edge)
+				}
+				create()
+				tx.record(func() {
+					delete(graph.egress, end)
+					delete(graph.ingress, end)
+				}, create)
+			}
+		}
+
+		old, exists := graph.egress[from][to]
+		added := &edge{eachEdge, weight, true, false}
+		graph.restoreEdge(from, to, added)
+		if exists {
+			tx.record(func() {
+				graph.restoreEdge(from, to, old)
+			}, func() {
+				graph.restoreEdge(from, to, added)
+			})
+		} else {
+			tx.record(func() {
+				graph.deleteEdge(from, to)
+			}, func() {
+				graph.restoreEdge(from, to, added)
+			})
+		}
+	}
+
+	return nil
+}
+
+func (tx *Tx) record(undo, redo func()) {
+	tx.changes = append(tx.changes, change{undo, redo})
+}
+
+func (tx *Tx) rollback() {
+	for i := len(tx.changes) - 1; i >= 0; i-- {
+		tx.changes[i].undo()
+	}
+	tx.changes = nil
+}
+
+// restoreMaps gets the function to put back the maps of the edges of the vertex as they are now.
+// The maps replaced by adding the vertex may hold the edges added before for it by its neighbors.
+func (graph *Graph) restoreMaps(id ID) func() {
+	egress, hasEgress := graph.egress[id]
+	ingress, hasIngress := graph.ingress[id]
+	return func() {
+		delete(graph.egress, id)
+		delete(graph.ingress, id)
+		if hasEgress {
+			graph.egress[id] = egress
+		}
+		if hasIngress {
+			graph.ingress[id] = ingress
+		}
+	}
+}
+
+// restoreVertex puts the deleted vertex back together with its edges.
+func (graph *Graph) restoreVertex(id ID, deleted *vertex, egress, ingress map[ID]*edge) {
+	graph.vertices[id] = deleted
+	graph.egress[id] = make(map[ID]*edge)
+	graph.ingress[id] = make(map[ID]*edge)
+	graph.invalidate()
+	for _, each := range graph.listeners {
+		each.vertexAdded(id)
+	}
+
+	for to, each := range egress {
+		graph.restoreEdge(id, to, each)
+	}
+	for from, each := range ingress {
+		if from != id {
+			graph.restoreEdge(from, id, each)
+		}
+	}
+}
+
+// restoreEdge puts the edge back, replacing the edge between the vertices if any.
+func (graph *Graph) restoreEdge(from, to ID, restored *edge) {
+	old, exists := graph.egress[from][to]
+	graph.egress[from][to] = restored
+	graph.ingress[to][from] = restored
+	graph.invalidate()
+	for _, each := range graph.listeners {
+		if exists {
+			each.edgeUpdated(from, to, old.weight, restored.weight)
+		} else {
+			each.edgeAdded(from, to, restored.weight)
+		}
+	}
+}
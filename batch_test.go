@@ -0,0 +1,194 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of batch", func() {
+	var (
+		graph  *Graph
+		events []string
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.AddVertex("S", "S")
+		graph.AddVertex("A", "A")
+		graph.AddVertex("T", "T")
+		graph.AddEdge("S", "A", 1, "SA")
+		graph.AddEdge("A", "T", 1, "AT")
+		graph.AddEdge("S", "T", 5, "ST")
+		graph.AddEdge("T", "T", 1, "TT")
+		events = nil
+		graph.OnVertexAdded(func(id ID) {
+			events = append(events, fmt.Sprint("vertex added ", id))
+		})
+		graph.OnVertexDeleted(func(id ID) {
+			events = append(events, fmt.Sprint("vertex deleted ", id))
+		})
+		graph.OnEdgeAdded(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge added ", from, to, weight))
+		})
+		graph.OnEdgeWeightUpdated(func(from, to ID, old, weight float64) {
+			events = append(events, fmt.Sprint("edge updated ", from, to, old, weight))
+		})
+		graph.OnEdgeDeleted(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge deleted ", from, to, weight))
+		})
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	expectOriginal := func() {
+		Expect(graph.vertices).Should(HaveLen(3))
+		for _, each := range []struct {
+			from, to ID
+			weight   float64
+			self     string
+		}{{"S", "A", 1, "SA"}, {"A", "T", 1, "AT"}, {"S", "T", 5, "ST"}, {"T", "T", 1, "TT"}} {
+			Expect(graph.GetEdgeWeight(each.from, each.to)).Should(BeEquivalentTo(each.weight))
+			Expect(graph.GetEdge(each.from, each.to)).Should(BeEquivalentTo(each.self))
+			Expect(graph.ingress[each.to][each.from]).Should(BeIdenticalTo(graph.egress[each.from][each.to]))
+		}
+		Expect(graph.egress["S"]).Should(HaveLen(2))
+		Expect(graph.egress["A"]).Should(HaveLen(1))
+		Expect(graph.egress["T"]).Should(HaveLen(1))
+		Expect(graph.ingress["T"]).Should(HaveLen(3))
+		Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+	}
+
+	Context("exception test", func() {
+		It("Given a graph, when the batch gets an error, then all the changes are rolled back.", func() {
+			failure := errors.New("failure")
+			err := graph.Batch(func(tx *Tx) error {
+				Expect(tx.AddVertex("B", "B")).ShouldNot(HaveOccurred())
+				Expect(tx.AddEdge("B", "T", 1, "BT")).ShouldNot(HaveOccurred())
+				Expect(tx.UpdateEdgeWeight("S", "T", 2)).ShouldNot(HaveOccurred())
+				Expect(tx.DeleteEdge("S", "A")).Should(BeEquivalentTo("SA"))
+				Expect(tx.DeleteVertex("T")).Should(BeEquivalentTo("T"))
+				Expect(tx.AddVertex("S", nil)).Should(HaveOccurred())
+				return failure
+			})
+			Expect(err).Should(Equal(failure))
+			Expect(graph.vertices).ShouldNot(HaveKey("B"))
+			expectOriginal()
+		})
+
+		It("Given a graph, when the batch panics, then all the changes are rolled back and the panic goes on.", func() {
+			Expect(func() {
+				graph.Batch(func(tx *Tx) error {
+					tx.DeleteVertex("A")
+					tx.AddVertex("A", "A")
+					tx.AddEdge("A", "S", 1, nil)
+					panic("failure")
+				})
+			}).Should(Panic())
+			expectOriginal()
+		})
+
+		It("Given a graph, when the batch fails, then the insertion order of the vertices is restored.", func() {
+			graph.Batch(func(tx *Tx) error {
+				tx.AddVertex("B", nil)
+				tx.DeleteVertex("S")
+				return errors.New("failure")
+			})
+			graph.AddVertex("C", nil)
+			Expect(graph.vertices["S"].order).Should(Equal(1))
+			Expect(graph.vertices["C"].order).Should(Equal(4))
+		})
+
+		It("Given a graph with observers, when the batch fails, then the observers are notified of the rollback in the reverse order.", func() {
+			graph.Batch(func(tx *Tx) error {
+				tx.UpdateEdgeWeight("S", "T", 2)
+				tx.DeleteEdge("S", "A")
+				return errors.New("failure")
+			})
+			Expect(events).Should(Equal([]string{
+				"edge updated ST5 2",
+				"edge deleted SA1",
+				"edge added SA1",
+				"edge updated ST2 5",
+			}))
+		})
+
+		It("Given a graph with a shortest path tree, when the batch fails, then the tree is the same as before.", func() {
+			tree, err := graph.ShortestPathTree("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			graph.Batch(func(tx *Tx) error {
+				tx.DeleteVertex("A")
+				tx.AddEdge("S", "S", -1, nil)
+				return errors.New("failure")
+			})
+			dist, prev, err := tree.Distances()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "A": 1, "T": 2}))
+			Expect(prev).Should(Equal(map[ID]ID{"S": nil, "A": "S", "T": "A"}))
+		})
+
+		It("Given a graph, when add a vertex with an invalid edge, then nothing is added and the observers are not notified.", func() {
+			err := graph.AddVertexWithEdges(&myVertex{"B", map[ID]float64{"S": 1, "X": 1}, map[ID]float64{"T": 1, "A": math.Inf(-1)}})
+			Expect(err).Should(HaveOccurred())
+			Expect(graph.vertices).ShouldNot(HaveKey("B"))
+			Expect(graph.egress).Should(HaveLen(3))
+			Expect(events).Should(BeEmpty())
+			expectOriginal()
+
+			err = graph.AddVertexWithEdges(&testVertex{"B", &myVertex{"S", map[ID]float64{"T": 10}, map[ID]float64{}}})
+			Expect(err).Should(HaveOccurred())
+			Expect(graph.vertices).ShouldNot(HaveKey("B"))
+			expectOriginal()
+		})
+
+		It("Given a graph, when a batch adding a vertex with edges fails, then the replaced edges and the unknown vertices are restored.", func() {
+			graph.AddVertexWithEdges(&myVertex{"C", map[ID]float64{"B": 3}, map[ID]float64{}})
+			graph.Batch(func(tx *Tx) error {
+				Expect(tx.AddVertexWithEdges(&myVertex{"B", map[ID]float64{"X": 1}, map[ID]float64{"C": 1}})).ShouldNot(HaveOccurred())
+				Expect(graph.GetEdgeWeight("C", "B")).Should(BeEquivalentTo(1))
+				Expect(graph.egress).Should(HaveLen(6))
+				return errors.New("failure")
+			})
+			Expect(graph.vertices).ShouldNot(HaveKey("B"))
+			Expect(graph.egress).Should(HaveLen(5))
+			Expect(graph.ingress).Should(HaveLen(5))
+			Expect(graph.egress["C"]["B"].weight).Should(BeEquivalentTo(3))
+			Expect(graph.ingress["B"]["C"]).Should(BeIdenticalTo(graph.egress["C"]["B"]))
+			Expect(graph.CheckIntegrity()).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given a graph, when the batch succeeds, then all the changes are kept.", func() {
+			err := graph.Batch(func(tx *Tx) error {
+				if err := tx.AddVertex("B", "B"); err != nil {
+					return err
+				}
+				if err := tx.AddEdge("S", "B", 1, nil); err != nil {
+					return err
+				}
+				tx.DeleteEdge("S", "A")
+				return tx.UpdateEdgeWeight("S", "T", 2)
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(graph.GetVertex("B")).Should(BeEquivalentTo("B"))
+			Expect(graph.GetEdgeWeight("S", "B")).Should(BeEquivalentTo(1))
+			Expect(graph.GetEdgeWeight("S", "A")).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(graph.GetEdgeWeight("S", "T")).Should(BeEquivalentTo(2))
+		})
+
+		It("Given a graph, when add a vertex with valid edges, then all the edges are added.", func() {
+			err := graph.AddVertexWithEdges(&myVertex{"B", map[ID]float64{"S": 1}, map[ID]float64{"T": 2}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(graph.GetEdgeWeight("B", "S")).Should(BeEquivalentTo(1))
+			Expect(graph.GetEdgeWeight("T", "B")).Should(BeEquivalentTo(2))
+			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,109 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+)
+
+// ZeroOneBFS gets the shortest path from one vertex to all other vertices in the graph whose edge weights are either 0 or 1.
+// It runs in linear time with a deque, and gets ErrWeightOutOfRange if any enabled edge has another weight.
+// https://en.wikipedia.org/wiki/Breadth-first_search#0-1_BFS
+func (graph *Graph) ZeroOneBFS(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	compact := graph.compact()
+	start, err := compact.vertexIndex(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = compact.checkWeights(1); err != nil {
+		return nil, nil, err
+	}
+
+	// the deque is made up of a stack popped first and a queue, the vertices by 0 edges are pushed into the stack.
+	distances, previous, visited := compact.initSearch(start)
+	front, back := []int32{start}, []int32{}
+	for len(front) != 0 || len(back) != 0 {
+		var min int32
+		if len(front) != 0 {
+			min, front = front[len(front)-1], front[:len(front)-1]
+		} else {
+			min, back = back[0], back[1:]
+		}
+		if visited[min] {
+			continue
+		}
+		visited[min] = true
+		for e := compact.offsets[min]; e < compact.offsets[min+1]; e++ {
+			if !compact.relax(distances, previous, visited, min, e) {
+				continue
+			}
+			if compact.weights[e] == 0 {
+				front = append(front, compact.targets[e])
+			} else {
+				back = append(back, compact.targets[e])
+			}
+		}
+	}
+
+	return compact.distMap(distances), compact.prevMap(previous), nil
+}
+
+// Dial gets the shortest path from one vertex to all other vertices in the graph whose edge weights are integers no more than the max weight.
+// It runs in linear time with maxWeight+1 circular buckets, and gets ErrWeightOutOfRange if any enabled edge has another weight.
+// https://en.wikipedia.org/wiki/Dijkstra%27s_algorithm#Specialized_variants
+func (graph *Graph) Dial(source ID, maxWeight int) (dist map[ID]float64, prev map[ID]ID, err error) {
+	compact := graph.compact()
+	start, err := compact.vertexIndex(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = compact.checkWeights(maxWeight); err != nil {
+		return nil, nil, err
+	}
+
+	if maxWeight < 0 {
+		maxWeight = 0
+	}
+	distances, previous, visited := compact.initSearch(start)
+	buckets := make([][]int32, maxWeight+1)
+	buckets[0] = []int32{start}
+	for current, queued := 0, 1; queued != 0; current++ {
+		bucket := current % len(buckets)
+		for len(buckets[bucket]) != 0 {
+			min := buckets[bucket][0]
+			buckets[bucket] = buckets[bucket][1:]
+			queued--
+			if visited[min] || distances[min] != float64(current) {
+				continue
+			}
+			visited[min] = true
+			for e := compact.offsets[min]; e < compact.offsets[min+1]; e++ {
+				to := compact.targets[e]
+				if compact.relax(distances, previous, visited, min, e) {
+					next := int(distances[to]) % len(buckets)
+					buckets[next] = append(buckets[next], to)
+					queued++
+				}
+			}
+		}
+	}
+
+	return compact.distMap(distances), compact.prevMap(previous), nil
+}
+
+// checkWeights checks that the weights of the edges are integers between 0 and the max weight.
+func (compact *compactGraph) checkWeights(maxWeight int) error {
+	if compact.err != nil {
+		return compact.err
+	}
+
+	for from := range compact.ids {
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			if weight := compact.weights[e]; weight != math.Trunc(weight) || weight > float64(maxWeight) {
+				return &EdgeError{ErrWeightOutOfRange, compact.ids[from], compact.ids[compact.targets[e]]}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+)
+
+var _ = Describe("Tests of ZeroOneBFS and Dial", func() {
+	var (
+		graph *Graph
+	)
+
+	newRandomGraph := func(maxWeight int) *Graph {
+		random := rand.New(rand.NewSource(1))
+		graph := NewGraph()
+		graph.SetDeterministic(true)
+		for i := 0; i < 200; i++ {
+			graph.AddVertex(i, nil)
+		}
+		for i := 0; i < 200; i++ {
+			for j := 0; j < 3; j++ {
+				graph.AddEdge(i, random.Intn(200), float64(random.Intn(maxWeight+1)), nil)
+			}
+		}
+		return graph
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "T", 2, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call zero one bfs or dial api with non-existed vertex, then get ErrVertexNotFound.", func() {
+			_, _, err := graph.ZeroOneBFS("X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			_, _, err = graph.Dial("X", 2)
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		})
+
+		It("Given a graph with weights out of range, when call zero one bfs or dial api, then get ErrWeightOutOfRange of the edge.", func() {
+			var edgeErr *EdgeError
+
+			dist, prev, err := graph.ZeroOneBFS("S")
+			Expect(errors.As(err, &edgeErr)).Should(BeTrue())
+			Expect(edgeErr.Err).Should(Equal(ErrWeightOutOfRange))
+			Expect(edgeErr.From).Should(BeEquivalentTo("S"))
+			Expect(edgeErr.To).Should(BeEquivalentTo("T"))
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+
+			_, _, err = graph.Dial("S", 1)
+			Expect(errors.Is(err, ErrWeightOutOfRange)).Should(BeTrue())
+			graph.UpdateEdgeWeight("S", "T", 1.5)
+			_, _, err = graph.Dial("S", 2)
+			Expect(errors.Is(err, ErrWeightOutOfRange)).Should(BeTrue())
+			graph.UpdateEdgeWeight("S", "T", -1)
+			_, _, err = graph.Dial("S", 2)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+		})
+
+		It("Given a graph with the edge out of range disabled, when call zero one bfs api, then the edge is not checked.", func() {
+			graph.DisableEdge("S", "T")
+			dist, prev, err := graph.ZeroOneBFS("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "T": math.Inf(1)}))
+			Expect(prev).Should(Equal(map[ID]ID{"S": nil, "T": nil}))
+		})
+	})
+
+	Context("algorithem test", func() {
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with 0 or 1 weights, when call zero one bfs api, then get the same distances as dijkstra.", func() {
+			graph = newRandomGraph(1)
+			expectedDist, _, _ := graph.Dijkstra(0)
+
+			dist, prev, err := graph.ZeroOneBFS(0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(expectedDist))
+			for to, from := range prev {
+				if from != nil {
+					Expect(dist[from] + graph.egress[from][to].weight).Should(Equal(dist[to]))
+				}
+			}
+		})
+
+		It("Given a graph with small integer weights, when call dial api, then get the same distances as dijkstra.", func() {
+			graph = newRandomGraph(5)
+			expectedDist, _, _ := graph.Dijkstra(0)
+
+			dist, prev, err := graph.Dial(0, 5)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(expectedDist))
+			for to, from := range prev {
+				if from != nil {
+					Expect(dist[from] + graph.egress[from][to].weight).Should(Equal(dist[to]))
+				}
+			}
+
+			dist, _, err = graph.Dial(0, 100)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(expectedDist))
+		})
+	})
+})
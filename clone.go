@@ -0,0 +1,156 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Cloner is implemented by the values of the vertices and edges which can be copied by a deep clone of the graph.
+type Cloner interface {
+	// Clone gets a copy of the value.
+	Clone() interface{}
+}
+
+// EqualOptions is the options to compare two graphs.
+type EqualOptions struct {
+	// Tolerance is the max absolute difference for two weights to be equal, the infinite weights are equal only to themselves.
+	Tolerance float64
+	// Values compares the values of the vertices and edges by reflect.DeepEqual as well.
+	Values bool
+}
+
+// Clone gets a copy of the graph, including its disabled edges, settings and version.
+// The values of the vertices and edges are shared with the graph, unless deep is true and they implement Cloner.
+// The observers, shortest path trees and journal of the graph are not copied.
+func (graph *Graph) Clone(deep bool) *Graph {
+	copyValue := func(value interface{}) interface{} {
+		return value
+	}
+	if deep {
+		copyValue = func(value interface{}) interface{} {
+			if cloner, ok := value.(Cloner); ok {
+				return cloner.Clone()
+			}
+			return value
+		}
+	}
+
+	clone := NewGraph()
+	clone.tolerance = graph.tolerance
+	clone.sequence = graph.sequence
+	clone.deterministic = graph.deterministic
+	clone.less = graph.less
+	clone.workers = graph.workers
+	clone.version = graph.version
+
+	for id, each := range graph.vertices {
+		clone.vertices[id] = &vertex{copyValue(each.self), each.enable, each.order}
+	}
+	for from, out := range graph.egress {
+		clone.egress[from] = make(map[ID]*edge, len(out))
+	}
+	for to, in := range graph.ingress {
+		clone.ingress[to] = make(map[ID]*edge, len(in))
+	}
+	for from, out := range graph.egress {
+		for to, each := range out {
+			copied := &edge{copyValue(each.self), each.weight, each.enable, each.changed}
+			clone.egress[from][to] = copied
+			if _, exists := clone.ingress[to]; !exists {
+				clone.ingress[to] = make(map[ID]*edge)
+			}
+			clone.ingress[to][from] = copied
+		}
+	}
+
+	return clone
+}
+
+// Equal reports whether the two graphs have the same vertices and the same edges with the equal weights.
+// The disabled edges are compared as the enabled ones, and the settings and versions of the graphs are not compared.
+func (graph *Graph) Equal(other *Graph, opts EqualOptions) bool {
+	if len(graph.vertices) != len(other.vertices) {
+		return false
+	}
+	for id, each := range graph.vertices {
+		that, exists := other.vertices[id]
+		if !exists || opts.Values && !reflect.DeepEqual(each.self, that.self) {
+			return false
+		}
+	}
+
+	edges := 0
+	for from := range graph.vertices {
+		for to, each := range graph.egress[from] {
+			if _, exists := graph.vertices[to]; !exists {
+				continue
+			}
+			edges++
+			that, exists := other.egress[from][to]
+			if !exists || opts.Values && !reflect.DeepEqual(each.self, that.self) {
+				return false
+			}
+			if each.weight != that.weight && !(math.Abs(each.weight-that.weight) <= opts.Tolerance) {
+				return false
+			}
+		}
+	}
+	for from := range other.vertices {
+		for to := range other.egress[from] {
+			if _, exists := other.vertices[to]; exists {
+				edges--
+			}
+		}
+	}
+
+	return edges == 0
+}
+
+// Hash gets the hash of the vertices and edges with their weights, which is the same for the graphs with the same content,
+// regardless of the order they are added, so that it can be the key to cache the query results of the graph.
+// The vertex ids are hashed by their types and formats, which must be stable, e.g. the basic types rather than pointers.
+// The weights are hashed exactly without the tolerance. The values, the disabled edges and the settings of the graph are not hashed.
+func (graph *Graph) Hash() uint64 {
+	hashes := make([]uint64, 0, len(graph.vertices))
+	for id := range graph.vertices {
+		hashes = append(hashes, hashOf("vertex", id))
+		for to, each := range graph.egress[id] {
+			if _, exists := graph.vertices[to]; exists {
+				weight := each.weight
+				if weight == 0 {
+					// -0 and 0 are the same weight.
+					weight = 0
+				}
+				hashes = append(hashes, hashOf("edge", id, to, math.Float64bits(weight)))
+			}
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i] < hashes[j]
+	})
+
+	hash := fnv.New64a()
+	buffer := make([]byte, 8)
+	for _, each := range hashes {
+		for i := range buffer {
+			buffer[i] = byte(each >> (8 * uint(i)))
+		}
+		hash.Write(buffer)
+	}
+
+	return hash.Sum64()
+}
+
+func hashOf(values ...interface{}) uint64 {
+	hash := fnv.New64a()
+	for _, each := range values {
+		fmt.Fprintf(hash, "%T:%#v;", each, each)
+	}
+
+	return hash.Sum64()
+}
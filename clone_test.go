@@ -0,0 +1,144 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+type clonedValue struct {
+	name string
+}
+
+func (value *clonedValue) Clone() interface{} {
+	return &clonedValue{value.name}
+}
+
+var _ = Describe("Tests of clone", func() {
+	var graph *Graph
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.SetDeterministic(true)
+		graph.SetTolerance(0.1)
+		graph.AddVertex("S", &clonedValue{"S"})
+		graph.AddVertex("A", "A")
+		graph.AddVertex("T", &clonedValue{"T"})
+		graph.AddEdge("S", "A", 1, &clonedValue{"SA"})
+		graph.AddEdge("A", "T", 1, nil)
+		graph.AddEdge("S", "T", 5, nil)
+		graph.DisableEdge("S", "T")
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	Context("exception test", func() {
+		It("Given two graphs with different vertices or edges, when compare and hash them, then they are not equal.", func() {
+			for _, change := range []func(other *Graph){
+				func(other *Graph) { other.AddVertex("B", nil) },
+				func(other *Graph) { other.DeleteVertex("A") },
+				func(other *Graph) { other.DeleteEdge("A", "T") },
+				func(other *Graph) { other.AddEdge("T", "S", 1, nil) },
+				func(other *Graph) { other.UpdateEdgeWeight("A", "T", 2) },
+				func(other *Graph) { other.UpdateEdgeWeight("A", "T", math.Inf(1)) },
+				func(other *Graph) {
+					other.DeleteEdge("A", "T")
+					other.AddEdge("T", "A", 1, nil)
+				},
+			} {
+				other := graph.Clone(false)
+				change(other)
+				Expect(graph.Equal(other, EqualOptions{Tolerance: 0.5})).Should(BeFalse())
+				Expect(other.Equal(graph, EqualOptions{Tolerance: 0.5})).Should(BeFalse())
+				Expect(other.Hash()).ShouldNot(Equal(graph.Hash()))
+			}
+		})
+
+		It("Given two graphs with different values, when compare them with the values, then they are not equal.", func() {
+			other := graph.Clone(false)
+			other.DeleteEdge("A", "T")
+			other.AddEdge("A", "T", 1, "AT")
+			Expect(graph.Equal(other, EqualOptions{})).Should(BeTrue())
+			Expect(graph.Equal(other, EqualOptions{Values: true})).Should(BeFalse())
+			Expect(other.Hash()).Should(Equal(graph.Hash()))
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given a graph, when clone it, then the clone is equal to it and changes independently.", func() {
+			clone := graph.Clone(false)
+			Expect(clone.Equal(graph, EqualOptions{Values: true})).Should(BeTrue())
+			Expect(clone.Hash()).Should(Equal(graph.Hash()))
+			Expect(clone.tolerance).Should(Equal(graph.tolerance))
+			Expect(clone.deterministic).Should(BeTrue())
+			Expect(clone.Version()).Should(Equal(graph.Version()))
+			Expect(clone.egress["S"]["T"].enable).Should(BeFalse())
+			Expect(clone.vertices["S"].order).Should(Equal(1))
+
+			clone.UpdateEdgeWeight("S", "A", 3)
+			clone.AddVertex("B", nil)
+			Expect(graph.GetEdgeWeight("S", "A")).Should(BeEquivalentTo(1))
+			Expect(graph.vertices).ShouldNot(HaveKey("B"))
+			Expect(clone.vertices["B"].order).Should(Equal(4))
+		})
+
+		It("Given a graph, when clone it shallowly or deeply, then the values are shared or copied.", func() {
+			shallow := graph.Clone(false)
+			Expect(shallow.vertices["S"].self).Should(BeIdenticalTo(graph.vertices["S"].self))
+			Expect(shallow.egress["S"]["A"].self).Should(BeIdenticalTo(graph.egress["S"]["A"].self))
+
+			deep := graph.Clone(true)
+			Expect(deep.vertices["S"].self).ShouldNot(BeIdenticalTo(graph.vertices["S"].self))
+			Expect(deep.egress["S"]["A"].self).ShouldNot(BeIdenticalTo(graph.egress["S"]["A"].self))
+			Expect(deep.ingress["A"]["S"]).Should(BeIdenticalTo(deep.egress["S"]["A"]))
+			Expect(deep.vertices["A"].self).Should(Equal("A"))
+			Expect(deep.Equal(graph, EqualOptions{Values: true})).Should(BeTrue())
+		})
+
+		It("Given a graph with observers and the journal, when clone it, then they are not copied.", func() {
+			graph.EnableJournal()
+			_, err := graph.ShortestPathTree("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			clone := graph.Clone(false)
+			Expect(clone.listeners).Should(BeEmpty())
+			Expect(clone.Undo()).Should(BeFalse())
+		})
+
+		It("Given two graphs with weights within the tolerance, when compare them, then they are equal.", func() {
+			other := graph.Clone(false)
+			other.UpdateEdgeWeight("A", "T", 1.05)
+			Expect(graph.Equal(other, EqualOptions{Tolerance: 0.1})).Should(BeTrue())
+			Expect(graph.Equal(other, EqualOptions{})).Should(BeFalse())
+		})
+
+		It("Given two graphs with the same content added in different orders, when hash them, then get the same hash.", func() {
+			other := NewGraph()
+			other.AddVertex("T", nil)
+			other.AddVertex("A", nil)
+			other.AddVertex("S", nil)
+			other.AddEdge("S", "T", 5, nil)
+			other.AddEdge("A", "T", 1, nil)
+			other.AddEdge("S", "A", 1, nil)
+			Expect(other.Hash()).Should(Equal(graph.Hash()))
+			Expect(other.Hash()).Should(Equal(other.Hash()))
+			Expect(graph.Freeze().Hash()).Should(Equal(graph.Hash()))
+
+			typed := NewGraph()
+			typed.AddVertex(1, nil)
+			stringed := NewGraph()
+			stringed.AddVertex("1", nil)
+			Expect(typed.Hash()).ShouldNot(Equal(stringed.Hash()))
+		})
+
+		It("Given a frozen graph, when clone it, then get a mutable graph equal to it.", func() {
+			clone := graph.Freeze().Clone(false)
+			Expect(clone.snapshot).Should(BeNil())
+			Expect(clone.Equal(graph, EqualOptions{})).Should(BeTrue())
+			Expect(clone.AddVertex("B", nil)).ShouldNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,242 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+	"math"
+)
+
+// compactGraph is a dense snapshot of the graph for the calculations, in which the vertices are indexed by int32
+// and the enabled edges are stored in CSR(compressed sparse row) arrays.
+// The ids are only translated when a calculation starts and when its result is returned.
+// https://en.wikipedia.org/wiki/Sparse_matrix#Compressed_sparse_row_(CSR,_CRS_or_Yale_format)
+type compactGraph struct {
+	ids           []ID
+	index         map[ID]int32
+	offsets       []int32
+	targets       []int32
+	weights       []float64
+	deterministic bool
+	// err is the error of the first negative edge, the calculations on a graph with negative edges fail.
+	err error
+}
+
+// compact gets the compact snapshot of the graph, which is built only once for a frozen graph.
+// The snapshot of a mutable graph is cached at its version and rebuilt after the graph is changed.
+func (graph *Graph) compact() *compactGraph {
+	if graph.snapshot != nil {
+		return graph.snapshot
+	}
+
+	graph.cacheLock.Lock()
+	defer graph.cacheLock.Unlock()
+	if graph.cache == nil || graph.cacheVersion != graph.version {
+		graph.cache = graph.buildCompact()
+		graph.cacheVersion = graph.version
+	}
+
+	return graph.cache
+}
+
+// invalidate drops the cached snapshot after the graph is changed,
+// including the changes not increasing the version such as disabling edges or rolling back a batch.
+func (graph *Graph) invalidate() {
+	graph.cache = nil
+}
+
+// buildCompact builds the compact snapshot of the graph.
+// The vertices are indexed in stable order in the deterministic mode, so the ties can be broken by the indices.
+func (graph *Graph) buildCompact() *compactGraph {
+	ids := graph.vertexIDs()
+	compact := &compactGraph{
+		ids:           ids,
+		index:         make(map[ID]int32, len(ids)),
+		offsets:       make([]int32, len(ids)+1),
+		deterministic: graph.deterministic,
+	}
+	for i, id := range ids {
+		compact.index[id] = int32(i)
+	}
+
+	for i, from := range ids {
+		for _, to := range graph.egressIDs(from) {
+			edge := graph.egress[from][to]
+			if edge.getWeight() < 0 && compact.err == nil {
+				compact.err = &EdgeError{ErrNegativeWeight, from, to}
+			}
+			if j, exists := compact.index[to]; exists && edge.enable {
+				compact.targets = append(compact.targets, j)
+				compact.weights = append(compact.weights, edge.getWeight())
+			}
+		}
+		compact.offsets[i+1] = int32(len(compact.targets))
+	}
+
+	return compact
+}
+
+// isCompactable reports whether the algebra is the shortest distance of the edge weights, which can be calculated on the compact graph.
+func isCompactable(algebra PathAlgebra) bool {
+	shortest, ok := algebra.(*shortestAlgebra)
+	return ok && shortest.metric == nil
+}
+
+// compactBlocking is the blocking translated to the indices of the compact graph.
+type compactBlocking struct {
+	vertices []bool
+	edges    map[int32]bool
+}
+
+// block translates the blocking, the vertices and edges not in the compact graph are ignored.
+func (compact *compactGraph) block(blocked *blocking) *compactBlocking {
+	if blocked == nil {
+		return nil
+	}
+
+	translated := &compactBlocking{make([]bool, len(compact.ids)), make(map[int32]bool)}
+	for id := range blocked.vertices {
+		if i, exists := compact.index[id]; exists {
+			translated.vertices[i] = true
+		}
+	}
+	for from, out := range blocked.edges {
+		i, exists := compact.index[from]
+		if !exists {
+			continue
+		}
+		for e := compact.offsets[i]; e < compact.offsets[i+1]; e++ {
+			if out[compact.ids[compact.targets[e]]] {
+				translated.edges[e] = true
+			}
+		}
+	}
+
+	return translated
+}
+
+func (blocked *compactBlocking) blocks(from, e int32) bool {
+	if blocked == nil {
+		return false
+	}
+
+	return blocked.vertices[from] || blocked.edges[e]
+}
+
+// dijkstra calculates the shortest distances from the source to the other vertices with the queue, the binary heap if nil.
+// It stops once the distance of the target is settled if the target is not negative.
+// The distance of an unreachable vertex is +Inf and its previous vertex is -1.
+func (compact *compactGraph) dijkstra(ctx context.Context, source, target int32, blocked *compactBlocking, newQueue QueueFactory) (dist []float64, prev []int32, err error) {
+	if compact.err != nil {
+		return nil, nil, compact.err
+	}
+
+	dist, prev, visited := compact.initSearch(source)
+	if newQueue == nil {
+		newQueue = NewBinaryHeap
+	}
+	queue := newQueue(len(compact.ids))
+	queue.Push(int(source), 0)
+
+	for i := 0; queue.Len() != 0; i++ {
+		if i%checkInterval == 0 && ctx.Err() != nil {
+			return dist, prev, ctx.Err()
+		}
+		vertex, key := queue.Pop()
+		min := int32(vertex)
+		if visited[min] || key > dist[min] {
+			continue
+		}
+		visited[min] = true
+		if min == target {
+			break
+		}
+		for e := compact.offsets[min]; e < compact.offsets[min+1]; e++ {
+			if !blocked.blocks(min, e) && compact.relax(dist, prev, visited, min, e) {
+				queue.Push(int(compact.targets[e]), dist[compact.targets[e]])
+			}
+		}
+	}
+
+	return
+}
+
+func (compact *compactGraph) initSearch(source int32) (dist []float64, prev []int32, visited []bool) {
+	dist = make([]float64, len(compact.ids))
+	prev = make([]int32, len(compact.ids))
+	visited = make([]bool, len(compact.ids))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[source] = 0
+
+	return
+}
+
+// relax relaxes the edge from the vertex, and reports whether the distance of its head is decreased.
+// In the deterministic mode, the first vertex in order is chosen among the predecessors with equal distances.
+func (compact *compactGraph) relax(dist []float64, prev []int32, visited []bool, from, e int32) bool {
+	to := compact.targets[e]
+	weight := dist[from] + compact.weights[e]
+	if weight < dist[to] {
+		dist[to] = weight
+		prev[to] = from
+		return true
+	}
+	if compact.deterministic && !visited[to] && prev[to] >= 0 && weight == dist[to] && from < prev[to] {
+		prev[to] = from
+	}
+
+	return false
+}
+
+// vertexIndex gets the index of the vertex, or an error if the vertex is not in the graph.
+func (compact *compactGraph) vertexIndex(id ID) (int32, error) {
+	if i, exists := compact.index[id]; exists {
+		return i, nil
+	}
+
+	return -1, &VertexError{ErrVertexNotFound, id}
+}
+
+func (compact *compactGraph) distMap(dist []float64) map[ID]float64 {
+	translated := make(map[ID]float64, len(dist))
+	for i, each := range dist {
+		translated[compact.ids[i]] = each
+	}
+
+	return translated
+}
+
+func (compact *compactGraph) prevMap(prev []int32) map[ID]ID {
+	translated := make(map[ID]ID, len(prev))
+	for i, each := range prev {
+		if each < 0 {
+			translated[compact.ids[i]] = nil
+		} else {
+			translated[compact.ids[i]] = compact.ids[each]
+		}
+	}
+
+	return translated
+}
+
+// getPath gets the path to the vertex by the previous vertices, nil if the vertex is the source or unreachable.
+func (compact *compactGraph) getPath(prev []int32, last int32) (path []ID) {
+	if prev[last] < 0 {
+		return nil
+	}
+
+	length := 1
+	for each := prev[last]; each >= 0; each = prev[each] {
+		length++
+	}
+	path = make([]ID, length)
+	for each := last; each >= 0; each = prev[each] {
+		length--
+		path[length] = compact.ids[each]
+	}
+
+	return
+}
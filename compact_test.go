@@ -0,0 +1,238 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/starwander/GoFibonacciHeap"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var _ = Describe("Tests of compact graph", func() {
+	var (
+		graph *Graph
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.SetDeterministic(true)
+		graph.AddVertex("S", nil)
+		graph.AddVertex("A", nil)
+		graph.AddVertex("B", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "A", 1, nil)
+		graph.AddEdge("S", "B", 2, nil)
+		graph.AddEdge("A", "T", 3, nil)
+		graph.AddEdge("B", "T", 1, nil)
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	It("Given a graph, when compact it, then get the vertices indexed in order and the enabled edges in CSR arrays.", func() {
+		graph.DisableEdge("A", "T")
+		compact := graph.compact()
+		Expect(compact.ids).Should(Equal([]ID{"S", "A", "B", "T"}))
+		Expect(compact.index).Should(Equal(map[ID]int32{"S": 0, "A": 1, "B": 2, "T": 3}))
+		Expect(compact.offsets).Should(Equal([]int32{0, 2, 2, 3, 3}))
+		Expect(compact.targets).Should(Equal([]int32{1, 2, 3}))
+		Expect(compact.weights).Should(Equal([]float64{1, 2, 1}))
+		Expect(compact.err).ShouldNot(HaveOccurred())
+	})
+
+	It("Given a mutable graph, when query it twice without changes, then the compact graph is built only once.", func() {
+		compact := graph.compact()
+		Expect(graph.compact()).Should(BeIdenticalTo(compact))
+		graph.GetPathWeight([]ID{"S", "A"})
+		Expect(graph.compact()).Should(BeIdenticalTo(compact))
+	})
+
+	It("Given a cached compact graph, when change the graph, then the compact graph is rebuilt with the change.", func() {
+		for _, change := range []func(){
+			func() { graph.UpdateEdgeWeight("S", "A", 5) },
+			func() { graph.AddVertex("X", nil) },
+			func() { graph.AddEdge("A", "B", 1, nil) },
+			func() { graph.DeleteEdge("A", "B") },
+			func() { graph.DeleteVertex("X") },
+			func() { graph.DisableEdge("S", "A") },
+			func() { graph.Reset() },
+			func() { graph.DisableVertex("B") },
+			func() { graph.DisablePath([]ID{"S"}) },
+			func() { graph.SetOrder(func(a, b ID) bool { return a.(string) > b.(string) }) },
+			func() { graph.SetDeterministic(false) },
+		} {
+			compact := graph.compact()
+			change()
+			Expect(graph.compact()).ShouldNot(BeIdenticalTo(compact))
+		}
+	})
+
+	It("Given a cached compact graph, when query in a batch rolled back, then the queries see the changes in the batch but not after the rollback.", func() {
+		dist, _, _ := graph.Dijkstra("S")
+		Expect(dist["T"]).Should(BeEquivalentTo(3))
+
+		graph.Batch(func(tx *Tx) error {
+			tx.UpdateEdgeWeight("B", "T", 5)
+			dist, _, _ := graph.Dijkstra("S")
+			Expect(dist["T"]).Should(BeEquivalentTo(4))
+			return errors.New("rollback")
+		})
+		dist, _, _ = graph.Dijkstra("S")
+		Expect(dist["T"]).Should(BeEquivalentTo(3))
+	})
+
+	It("Given a graph with negative edge, when calculate on its compact graph, then get ErrNegativeWeight.", func() {
+		graph.UpdateEdgeWeight("B", "T", -1)
+		_, _, err := graph.compact().dijkstra(context.Background(), 0, -1, nil, nil)
+		Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+	})
+
+	It("Given a blocking, when calculate on the compact graph, then the blocked vertices and edges are skipped.", func() {
+		blocked := newBlocking()
+		blocked.blockEdge("S", "B")
+		blocked.blockVertex("X")
+		compact := graph.compact()
+		dist, prev, err := compact.dijkstra(context.Background(), 0, 3, compact.block(blocked), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist[3]).Should(BeEquivalentTo(4))
+		Expect(compact.getPath(prev, 3)).Should(Equal([]ID{"S", "A", "T"}))
+
+		blocked.blockVertex("A")
+		dist, prev, err = compact.dijkstra(context.Background(), 0, 3, compact.block(blocked), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist[3]).Should(BeEquivalentTo(math.Inf(1)))
+		Expect(compact.getPath(prev, 3)).Should(BeNil())
+	})
+})
+
+func newBenchmarkGraph(vertices, edges int) *Graph {
+	random := rand.New(rand.NewSource(1))
+	graph := NewGraph()
+	for i := 0; i < vertices; i++ {
+		graph.AddVertex(i, nil)
+	}
+	for i := 0; i < vertices; i++ {
+		for j := 0; j < edges; j++ {
+			graph.AddEdge(i, random.Intn(vertices), float64(1+random.Intn(100)), nil)
+		}
+	}
+
+	return graph
+}
+
+func BenchmarkDijkstra(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.Dijkstra(0)
+	}
+}
+
+func BenchmarkDijkstraOnMaps(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dijkstraOnMaps(graph, 0)
+	}
+}
+
+// dijkstraOnMaps is the original implementation of Dijkstra on the maps of the graph, kept as the baseline of the benchmarks.
+func dijkstraOnMaps(graph *Graph, source ID) (dist map[ID]float64, prev map[ID]ID) {
+	dist = make(map[ID]float64)
+	prev = make(map[ID]ID)
+	heap := fibHeap.NewFibHeap()
+
+	for id := range graph.vertices {
+		prev[id] = nil
+		if id != source {
+			dist[id] = math.Inf(1)
+			heap.Insert(id, math.Inf(1))
+		} else {
+			dist[id] = 0
+			heap.Insert(id, 0)
+		}
+	}
+
+	for heap.Num() != 0 {
+		min, _ := heap.ExtractMin()
+		for to, edge := range graph.egress[min] {
+			if !edge.enable {
+				continue
+			}
+			if dist[min]+edge.getWeight() < dist[to] {
+				heap.DecreaseKey(to, dist[min]+edge.getWeight())
+				prev[to] = min
+				dist[to] = dist[min] + edge.getWeight()
+			}
+		}
+	}
+
+	return
+}
+
+func BenchmarkYen(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.Yen(0, 9999, 10)
+	}
+}
+
+func BenchmarkYenOnMaps(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		search := graph.newYenSearch(context.Background(), 0, 9999, Shortest(nil))
+		search.compact = nil
+		for k := 0; k < 10; k++ {
+			search.next()
+		}
+	}
+}
+
+func BenchmarkKisp(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.Kisp(0, 9999, 10)
+	}
+}
+
+func BenchmarkKispOnMaps(b *testing.B) {
+	graph := newBenchmarkGraph(10000, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		kispOnMaps(graph, 0, 9999, 10)
+	}
+}
+
+// kispOnMaps is the original implementation of Kisp on the maps of the graph, which disables the edges of the paths found
+// and runs dijkstraOnMaps again, kept as the baseline of the benchmarks.
+func kispOnMaps(graph *Graph, source, destination ID, topK int) ([]float64, [][]ID) {
+	distTopK := make([]float64, topK)
+	pathTopK := make([][]ID, topK)
+	for i := 0; i < topK; i++ {
+		distTopK[i] = math.Inf(1)
+	}
+
+	dist, prev := dijkstraOnMaps(graph, source)
+	distTopK[0] = dist[destination]
+	pathTopK[0] = getPath(prev, destination)
+	for k := 1; k < topK && distTopK[k-1] != math.Inf(1); k++ {
+		for i := 0; i < len(pathTopK[k-1])-1; i++ {
+			graph.DisableEdge(pathTopK[k-1][i], pathTopK[k-1][i+1])
+		}
+		dist, prev = dijkstraOnMaps(graph, source)
+		distTopK[k] = dist[destination]
+		pathTopK[k] = getPath(prev, destination)
+	}
+	graph.Reset()
+
+	return distTopK, pathTopK
+}
@@ -0,0 +1,660 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+	"sort"
+)
+
+// The max numbers of vertices settled by a witness search when the contraction is simulated to order the vertices and when it is done,
+// a shortcut is added if no witness is found within it.
+const (
+	simulationLimit = 50
+	witnessLimit    = 500
+)
+
+// ContractionHierarchy is the preprocessed form of a graph which answers point-to-point shortest path queries
+// by bidirectional searches along the edges to more important vertices.
+// Queries can run concurrently, but not together with the changes of the graph.
+// The hierarchy is repaired as the edge weights of the graph are updated, while the other changes make it stale until it is rebuilt.
+// Disabling and enabling edges are not tracked either, Rebuild must be called after them.
+// https://en.wikipedia.org/wiki/Contraction_hierarchies
+type ContractionHierarchy struct {
+	graph   *Graph
+	compact *compactGraph
+	// err is the error of the hierarchy getting stale, e.g. a vertex added or a negative weight, the queries fail until it is rebuilt.
+	err error
+	// order is the vertices by the order of contraction, and rank is the position of each vertex in it.
+	order []int32
+	rank  []int32
+	// out and in is the edges of the overlay from and to each vertex, sorted by the index of the other end and kept between the updates.
+	// The edges to the vertices contracted after a vertex are its upward edges, and the ones from them are its downward edges.
+	out [][]*chEdge
+	in  [][]*chEdge
+	// shortcuts is the shortcuts added by contracting each vertex, sorted by the indices of their ends.
+	shortcuts [][]chShortcut
+	// witnesses is the vertices whose witness searches found a witness path through each edge, and witnessed is the edges of each vertex in it.
+	witnesses map[[2]int32][]int32
+	witnessed [][][2]int32
+	search    *chWitness
+}
+
+// chArc is an edge in the hierarchy, which is a shortcut if the middle vertex is not negative.
+type chArc struct {
+	weight float64
+	middle int32
+}
+
+// chEdge is an edge of the overlay, made up of the original edge if any and the shortcuts through the vertices contracted before both ends.
+// The shortcut through a vertex is only in the overlay after the vertex is contracted.
+type chEdge struct {
+	from, to    int32
+	original    float64
+	hasOriginal bool
+	// shortcuts is the shortcuts sorted by their middle vertices.
+	shortcuts []chArc
+	// arc is the best of the original edge and the shortcuts.
+	arc chArc
+}
+
+type chShortcut struct {
+	from, to int32
+	arc      chArc
+}
+
+// chWitness is the state of the last witness search, so the arrays are allocated only once.
+type chWitness struct {
+	dist    []float64
+	prev    []int32
+	settled []bool
+	touched []int32
+}
+
+// BuildContractionHierarchy contracts the vertices one by one in the order of edge difference,
+// adding shortcuts to keep the shortest distances among the remaining vertices.
+// The hierarchy subscribes to the changes of the graph until it is closed.
+// Try to build the hierarchy of a graph with negative edges will get ErrNegativeWeight.
+func (graph *Graph) BuildContractionHierarchy() (*ContractionHierarchy, error) {
+	ch := &ContractionHierarchy{graph: graph}
+	if err := ch.Rebuild(); err != nil {
+		return nil, err
+	}
+	graph.subscribe(ch)
+
+	return ch, nil
+}
+
+// Close unsubscribes the hierarchy from the changes of the graph, the hierarchy is not repaired any more.
+func (ch *ContractionHierarchy) Close() {
+	ch.graph.unsubscribe(ch)
+}
+
+// Rebuild contracts all the vertices of the graph again, the hierarchy is no longer stale if it succeeds.
+func (ch *ContractionHierarchy) Rebuild() error {
+	compact := ch.graph.compact()
+	if compact.err != nil {
+		ch.err = compact.err
+		return compact.err
+	}
+
+	size := len(compact.ids)
+	*ch = ContractionHierarchy{
+		graph:     ch.graph,
+		compact:   compact,
+		order:     make([]int32, 0, size),
+		rank:      make([]int32, size),
+		out:       make([][]*chEdge, size),
+		in:        make([][]*chEdge, size),
+		shortcuts: make([][]chShortcut, size),
+		witnesses: make(map[[2]int32][]int32),
+		witnessed: make([][][2]int32, size),
+		search: &chWitness{
+			dist:    make([]float64, size),
+			prev:    make([]int32, size),
+			settled: make([]bool, size),
+		},
+	}
+	for from := range compact.ids {
+		ch.rank[from] = math.MaxInt32
+		ch.search.dist[from] = math.Inf(1)
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			if to := compact.targets[e]; to != int32(from) {
+				edge := &chEdge{from: int32(from), to: to, original: compact.weights[e], hasOriginal: true}
+				edge.refresh()
+				ch.out[from] = append(ch.out[from], edge)
+				ch.in[to] = append(ch.in[to], edge)
+			}
+		}
+		out := ch.out[from]
+		sort.Slice(out, func(i, j int) bool { return out[i].to < out[j].to })
+	}
+
+	neighbors := make([]int, size)
+	priority := func(v int32) float64 {
+		now := int32(len(ch.order))
+		added := len(ch.findShortcuts(v, now, simulationLimit, false))
+		return float64(added - ch.degree(v, now) + neighbors[v])
+	}
+	queue := NewBinaryHeap(size)
+	for v := range compact.ids {
+		queue.Push(v, priority(int32(v)))
+	}
+	for queue.Len() != 0 {
+		vertex, key := queue.Pop()
+		v := int32(vertex)
+		if ch.rank[v] != math.MaxInt32 {
+			continue
+		}
+		if current := priority(v); current > key {
+			queue.Push(vertex, current)
+			continue
+		}
+
+		now := int32(len(ch.order))
+		for _, edge := range ch.in[v] {
+			if _, exists := edge.weightAt(ch.rank, now); exists && ch.rank[edge.from] > now {
+				neighbors[edge.from]++
+			}
+		}
+		for _, edge := range ch.out[v] {
+			if _, exists := edge.weightAt(ch.rank, now); exists && ch.rank[edge.to] > now {
+				neighbors[edge.to]++
+			}
+		}
+		ch.rank[v] = now
+		ch.order = append(ch.order, v)
+		ch.contract(v)
+	}
+
+	return nil
+}
+
+// UpdateEdgeWeight updates the weight of the edge in the graph, which the hierarchy is repaired for as the UpdateEdgeWeight of the graph.
+// Try to update an edge with negative weight will get ErrNegativeWeight, other errors are the same as the UpdateEdgeWeight of the graph.
+func (ch *ContractionHierarchy) UpdateEdgeWeight(from ID, to ID, weight float64) error {
+	if weight < 0 {
+		return &EdgeError{ErrNegativeWeight, from, to}
+	}
+
+	return ch.graph.UpdateEdgeWeight(from, to, weight)
+}
+
+func (ch *ContractionHierarchy) vertexAdded(id ID) {
+	ch.stale(&VertexError{ErrStale, id})
+}
+
+func (ch *ContractionHierarchy) vertexDeleted(id ID) {
+	ch.stale(&VertexError{ErrStale, id})
+}
+
+func (ch *ContractionHierarchy) edgeAdded(from, to ID, weight float64) {
+	ch.stale(&EdgeError{ErrStale, from, to})
+}
+
+func (ch *ContractionHierarchy) edgeDeleted(from, to ID, weight float64) {
+	ch.stale(&EdgeError{ErrStale, from, to})
+}
+
+// edgeUpdated contracts the affected vertices again in the same order on the overlay kept from the last contraction:
+// the lower end of the edge if its best weight changes, and the vertices whose witness paths go through it if the weight increases.
+// Contracting a vertex again may in turn affect the vertices contracted after it, the others are kept.
+func (ch *ContractionHierarchy) edgeUpdated(from, to ID, old, weight float64) {
+	if ch.err != nil {
+		return
+	}
+	if weight < 0 {
+		ch.stale(&EdgeError{ErrNegativeWeight, from, to})
+		return
+	}
+
+	i, existsFrom := ch.compact.index[from]
+	j, existsTo := ch.compact.index[to]
+	if !existsFrom || !existsTo {
+		return
+	}
+	edge := ch.edge(i, j)
+	if edge == nil || !edge.hasOriginal || edge.original == weight {
+		return
+	}
+
+	before := edge.arc
+	heavier := weight > edge.original
+	edge.original = weight
+	edge.refresh()
+	ch.repair(ch.affect(nil, edge, before, heavier, -1))
+}
+
+// stale keeps the first error making the hierarchy stale.
+func (ch *ContractionHierarchy) stale(err error) {
+	if ch.err == nil {
+		ch.err = err
+	}
+}
+
+// ShortestPath gets the shortest path between two vertices in the hierarchy, with the shortcuts unpacked into the original vertices.
+// It gets +Inf and a nil path if the destination is unreachable.
+// Try to query a vertex not in the hierarchy will get an error, and try to query a stale hierarchy will get ErrStale or ErrNegativeWeight.
+func (ch *ContractionHierarchy) ShortestPath(source, destination ID) (dist float64, path []ID, err error) {
+	if ch.err != nil {
+		return math.Inf(1), nil, ch.err
+	}
+	s, err := ch.compact.vertexIndex(source)
+	if err != nil {
+		return math.Inf(1), nil, err
+	}
+	t, err := ch.compact.vertexIndex(destination)
+	if err != nil {
+		return math.Inf(1), nil, err
+	}
+
+	forward, backward := newCHSearch(s), newCHSearch(t)
+	best, meet := math.Inf(1), int32(-1)
+	for !forward.done || !backward.done {
+		if !forward.done {
+			forward.step(ch, true, backward, &best, &meet)
+		}
+		if !backward.done {
+			backward.step(ch, false, forward, &best, &meet)
+		}
+	}
+	if meet < 0 {
+		return math.Inf(1), nil, nil
+	}
+
+	var chain []int32
+	for v := meet; v >= 0; v = forward.prev[v] {
+		chain = append(chain, v)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	for v := backward.prev[meet]; v >= 0; v = backward.prev[v] {
+		chain = append(chain, v)
+	}
+
+	path = []ID{ch.compact.ids[s]}
+	for i := 1; i < len(chain); i++ {
+		path = ch.unpack(path, chain[i-1], chain[i])
+	}
+
+	return best, path, nil
+}
+
+// unpack appends the original vertices after the first one on the edge in the hierarchy.
+func (ch *ContractionHierarchy) unpack(path []ID, from, to int32) []ID {
+	arc := ch.edge(from, to).arc
+	if arc.middle < 0 {
+		return append(path, ch.compact.ids[to])
+	}
+
+	path = ch.unpack(path, from, arc.middle)
+	return ch.unpack(path, arc.middle, to)
+}
+
+// repair contracts the affected vertices again by their ranks, together with the vertices contracted after them which are affected in turn.
+func (ch *ContractionHierarchy) repair(affected []int32) {
+	queued := make(map[int32]bool)
+	queue := NewBinaryHeap(0)
+	push := func(vertices []int32) {
+		for _, v := range vertices {
+			if !queued[v] {
+				queued[v] = true
+				queue.Push(int(v), float64(ch.rank[v]))
+			}
+		}
+	}
+
+	push(affected)
+	for queue.Len() != 0 {
+		v, _ := queue.Pop()
+		push(ch.contract(int32(v)))
+	}
+}
+
+// contract finds the shortcuts of the vertex among its neighbors contracted after it, and replaces the old shortcuts of the vertex with them.
+// It gets the vertices to contract again for the replaced shortcuts, which are all contracted after the vertex.
+func (ch *ContractionHierarchy) contract(v int32) (affected []int32) {
+	ch.unwitness(v)
+	shortcuts := ch.findShortcuts(v, ch.rank[v], witnessLimit, true)
+	old := ch.shortcuts[v]
+	ch.shortcuts[v] = shortcuts
+
+	for i, j := 0, 0; i < len(old) || j < len(shortcuts); {
+		switch {
+		case j == len(shortcuts) || i < len(old) && shortcutBefore(old[i], shortcuts[j]):
+			affected = ch.putShortcut(affected, old[i], true)
+			i++
+		case i == len(old) || shortcutBefore(shortcuts[j], old[i]):
+			affected = ch.putShortcut(affected, shortcuts[j], false)
+			j++
+		default:
+			if old[i].arc.weight != shortcuts[j].arc.weight {
+				affected = ch.putShortcut(affected, shortcuts[j], false)
+			}
+			i++
+			j++
+		}
+	}
+
+	return
+}
+
+func shortcutBefore(a, b chShortcut) bool {
+	if a.from != b.from {
+		return a.from < b.from
+	}
+
+	return a.to < b.to
+}
+
+// putShortcut sets or removes the shortcut on its edge, and appends the vertices affected by it.
+func (ch *ContractionHierarchy) putShortcut(affected []int32, shortcut chShortcut, remove bool) []int32 {
+	edge := ch.edge(shortcut.from, shortcut.to)
+	if edge == nil {
+		edge = ch.addEdge(shortcut.from, shortcut.to)
+	}
+	before := edge.arc
+	middle := shortcut.arc.middle
+	i := sort.Search(len(edge.shortcuts), func(i int) bool { return edge.shortcuts[i].middle >= middle })
+	heavier := remove
+	switch {
+	case remove:
+		edge.shortcuts = append(edge.shortcuts[:i], edge.shortcuts[i+1:]...)
+	case i < len(edge.shortcuts) && edge.shortcuts[i].middle == middle:
+		heavier = shortcut.arc.weight > edge.shortcuts[i].weight
+		edge.shortcuts[i] = shortcut.arc
+	default:
+		edge.shortcuts = append(edge.shortcuts, chArc{})
+		copy(edge.shortcuts[i+1:], edge.shortcuts[i:])
+		edge.shortcuts[i] = shortcut.arc
+	}
+	edge.refresh()
+	if !edge.hasOriginal && len(edge.shortcuts) == 0 {
+		ch.removeEdge(edge)
+	}
+
+	return ch.affect(affected, edge, before, heavier, ch.rank[middle])
+}
+
+// affect appends the vertices affected by the change of the edge from the arc before: the lower end of the edge if its best weight changes,
+// and the vertices contracted after the rank whose witness paths go through the edge if a part of it becomes heavier.
+func (ch *ContractionHierarchy) affect(affected []int32, edge *chEdge, before chArc, heavier bool, rank int32) []int32 {
+	if edge.arc.weight != before.weight {
+		if ch.rank[edge.from] < ch.rank[edge.to] {
+			affected = append(affected, edge.from)
+		} else {
+			affected = append(affected, edge.to)
+		}
+	}
+	if heavier {
+		for _, v := range ch.witnesses[[2]int32{edge.from, edge.to}] {
+			if ch.rank[v] > rank {
+				affected = append(affected, v)
+			}
+		}
+	}
+
+	return affected
+}
+
+// degree gets the number of the edges of the vertex in the overlay when the vertex of the rank is contracted.
+func (ch *ContractionHierarchy) degree(v int32, rank int32) (degree int) {
+	for _, edge := range ch.in[v] {
+		if _, exists := edge.weightAt(ch.rank, rank); exists && ch.rank[edge.from] > rank {
+			degree++
+		}
+	}
+	for _, edge := range ch.out[v] {
+		if _, exists := edge.weightAt(ch.rank, rank); exists && ch.rank[edge.to] > rank {
+			degree++
+		}
+	}
+
+	return
+}
+
+// findShortcuts gets the shortcuts needed to contract the vertex when the vertex of the rank is contracted,
+// with the witness searches settling at most limit vertices. The witness paths are recorded for the vertex if record is true.
+func (ch *ContractionHierarchy) findShortcuts(v int32, rank int32, limit int, record bool) (shortcuts []chShortcut) {
+	search := ch.search
+	for _, in := range ch.in[v] {
+		u := in.from
+		inWeight, exists := in.weightAt(ch.rank, rank)
+		if !exists || ch.rank[u] <= rank {
+			continue
+		}
+		bound := math.Inf(-1)
+		for _, out := range ch.out[v] {
+			if outWeight, exists := out.weightAt(ch.rank, rank); exists && out.to != u && ch.rank[out.to] > rank {
+				bound = math.Max(bound, inWeight+outWeight)
+			}
+		}
+		if math.IsInf(bound, -1) {
+			continue
+		}
+
+		ch.witnessSearch(u, v, rank, bound, limit)
+		for _, out := range ch.out[v] {
+			w := out.to
+			outWeight, exists := out.weightAt(ch.rank, rank)
+			if !exists || w == u || ch.rank[w] <= rank {
+				continue
+			}
+			via := inWeight + outWeight
+			if search.dist[w] > via {
+				shortcuts = append(shortcuts, chShortcut{u, w, chArc{via, v}})
+				continue
+			}
+			for x := w; record && x != u; x = search.prev[x] {
+				ch.witness(v, [2]int32{search.prev[x], x})
+			}
+		}
+	}
+
+	return
+}
+
+// witnessSearch calculates the distances from the source avoiding the vertex when the vertex of the rank is contracted,
+// until the distances exceed the bound or limit vertices are settled. The distances and previous vertices are kept until the next search.
+func (ch *ContractionHierarchy) witnessSearch(source, avoid int32, rank int32, bound float64, limit int) {
+	search := ch.search
+	for _, v := range search.touched {
+		search.dist[v] = math.Inf(1)
+		search.settled[v] = false
+	}
+	search.touched = append(search.touched[:0], source)
+	search.dist[source] = 0
+	queue := NewBinaryHeap(0)
+	queue.Push(int(source), 0)
+
+	for settled := 0; queue.Len() != 0 && settled < limit; {
+		vertex, key := queue.Pop()
+		min := int32(vertex)
+		if search.settled[min] || key > search.dist[min] {
+			continue
+		}
+		if key > bound {
+			break
+		}
+		search.settled[min] = true
+		settled++
+		for _, edge := range ch.out[min] {
+			to := edge.to
+			weight, exists := edge.weightAt(ch.rank, rank)
+			if !exists || to == avoid || ch.rank[to] <= rank || key+weight >= search.dist[to] {
+				continue
+			}
+			if math.IsInf(search.dist[to], 1) {
+				search.touched = append(search.touched, to)
+			}
+			search.dist[to] = key + weight
+			search.prev[to] = min
+			queue.Push(int(to), search.dist[to])
+		}
+	}
+}
+
+// witness records the edge on a witness path of the vertex.
+func (ch *ContractionHierarchy) witness(v int32, arc [2]int32) {
+	vertices := ch.witnesses[arc]
+	if len(vertices) != 0 && vertices[len(vertices)-1] == v {
+		return
+	}
+	ch.witnesses[arc] = append(vertices, v)
+	ch.witnessed[v] = append(ch.witnessed[v], arc)
+}
+
+// unwitness removes the witness paths of the vertex.
+func (ch *ContractionHierarchy) unwitness(v int32) {
+	for _, arc := range ch.witnessed[v] {
+		vertices := ch.witnesses[arc]
+		for i := range vertices {
+			if vertices[i] == v {
+				vertices = append(vertices[:i], vertices[i+1:]...)
+				break
+			}
+		}
+		if len(vertices) == 0 {
+			delete(ch.witnesses, arc)
+		} else {
+			ch.witnesses[arc] = vertices
+		}
+	}
+	ch.witnessed[v] = nil
+}
+
+// edge gets the edge of the overlay between the vertices, nil if there is none.
+func (ch *ContractionHierarchy) edge(from, to int32) *chEdge {
+	out := ch.out[from]
+	i := sort.Search(len(out), func(i int) bool { return out[i].to >= to })
+	if i < len(out) && out[i].to == to {
+		return out[i]
+	}
+
+	return nil
+}
+
+// addEdge adds an edge without weight into the overlay, keeping the edges of both ends sorted.
+func (ch *ContractionHierarchy) addEdge(from, to int32) *chEdge {
+	edge := &chEdge{from: from, to: to}
+	edge.refresh()
+	out := ch.out[from]
+	i := sort.Search(len(out), func(i int) bool { return out[i].to >= to })
+	out = append(out, nil)
+	copy(out[i+1:], out[i:])
+	out[i] = edge
+	ch.out[from] = out
+
+	in := ch.in[to]
+	i = sort.Search(len(in), func(i int) bool { return in[i].from >= from })
+	in = append(in, nil)
+	copy(in[i+1:], in[i:])
+	in[i] = edge
+	ch.in[to] = in
+
+	return edge
+}
+
+// removeEdge removes the edge from the overlay.
+func (ch *ContractionHierarchy) removeEdge(edge *chEdge) {
+	out := ch.out[edge.from]
+	i := sort.Search(len(out), func(i int) bool { return out[i].to >= edge.to })
+	ch.out[edge.from] = append(out[:i], out[i+1:]...)
+
+	in := ch.in[edge.to]
+	i = sort.Search(len(in), func(i int) bool { return in[i].from >= edge.from })
+	ch.in[edge.to] = append(in[:i], in[i+1:]...)
+}
+
+// refresh sets the arc of the edge to the best of the original edge and the shortcuts, the original edge and the lower middle vertex win ties.
+func (edge *chEdge) refresh() {
+	edge.arc = chArc{math.Inf(1), -1}
+	if edge.hasOriginal {
+		edge.arc.weight = edge.original
+	}
+	for _, shortcut := range edge.shortcuts {
+		if shortcut.weight < edge.arc.weight || !edge.hasOriginal && edge.arc.middle < 0 {
+			edge.arc = shortcut
+		}
+	}
+}
+
+// weightAt gets the weight of the edge when the vertex of the rank is contracted, which has only the shortcuts through the vertices contracted before it.
+// It gets false if the edge is not in the overlay then.
+func (edge *chEdge) weightAt(rank []int32, r int32) (float64, bool) {
+	if edge.arc.middle < 0 && edge.hasOriginal || edge.arc.middle >= 0 && rank[edge.arc.middle] < r {
+		return edge.arc.weight, true
+	}
+
+	weight, exists := edge.original, edge.hasOriginal
+	for _, shortcut := range edge.shortcuts {
+		if rank[shortcut.middle] < r && (!exists || shortcut.weight < weight) {
+			weight, exists = shortcut.weight, true
+		}
+	}
+
+	return weight, exists
+}
+
+// chSearch is one direction of the bidirectional search in the hierarchy.
+type chSearch struct {
+	dist    map[int32]float64
+	prev    map[int32]int32
+	settled map[int32]bool
+	queue   PriorityQueue
+	done    bool
+}
+
+func newCHSearch(source int32) *chSearch {
+	search := &chSearch{
+		dist:    map[int32]float64{source: 0},
+		prev:    map[int32]int32{source: -1},
+		settled: make(map[int32]bool),
+		queue:   NewBinaryHeap(0),
+	}
+	search.queue.Push(int(source), 0)
+
+	return search
+}
+
+// step settles one vertex along the upward edges if forward, otherwise the downward edges,
+// and updates the best distance if the other search has reached the vertex.
+// The search is done once its queue is empty or its distances are no less than the best distance.
+func (search *chSearch) step(ch *ContractionHierarchy, forward bool, other *chSearch, best *float64, meet *int32) {
+	for search.queue.Len() != 0 {
+		vertex, key := search.queue.Pop()
+		min := int32(vertex)
+		if search.settled[min] || key > search.dist[min] {
+			continue
+		}
+		if key >= *best {
+			break
+		}
+		search.settled[min] = true
+		if d, exists := other.dist[min]; exists && key+d < *best {
+			*best, *meet = key+d, min
+		}
+		edges := ch.out[min]
+		if !forward {
+			edges = ch.in[min]
+		}
+		for _, edge := range edges {
+			to := edge.to
+			if !forward {
+				to = edge.from
+			}
+			if ch.rank[to] < ch.rank[min] {
+				continue
+			}
+			if d, exists := search.dist[to]; !exists || key+edge.arc.weight < d {
+				search.dist[to] = key + edge.arc.weight
+				search.prev[to] = min
+				search.queue.Push(int(to), search.dist[to])
+			}
+		}
+		return
+	}
+
+	search.done = true
+}
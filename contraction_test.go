@@ -0,0 +1,333 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var _ = Describe("Tests of ContractionHierarchy", func() {
+	var (
+		graph *Graph
+	)
+
+	expectSameAsDijkstra := func(graph *Graph, ch *ContractionHierarchy) {
+		for source := range graph.vertices {
+			expectedDist, _, err := graph.Dijkstra(source)
+			Expect(err).ShouldNot(HaveOccurred())
+			for destination, expected := range expectedDist {
+				dist, path, err := ch.ShortestPath(source, destination)
+				Expect(err).ShouldNot(HaveOccurred())
+				if math.IsInf(expected, 1) {
+					Expect(dist).Should(Equal(expected), "%v to %v", source, destination)
+					Expect(path).Should(BeNil())
+					continue
+				}
+				Expect(dist).Should(BeNumerically("~", expected, 1e-9), "%v to %v", source, destination)
+				Expect(path[0]).Should(Equal(source))
+				Expect(path[len(path)-1]).Should(Equal(destination))
+				Expect(graph.GetPathWeight(path)).Should(BeNumerically("~", expected, 1e-9))
+			}
+		}
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "T", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with negative edge, when build contraction hierarchy, then get ErrNegativeWeight.", func() {
+			graph.UpdateEdgeWeight("S", "T", -1)
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(ch).Should(BeNil())
+		})
+
+		It("Given a contraction hierarchy, when query with non-existed vertex, then get ErrVertexNotFound.", func() {
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dist, path, err := ch.ShortestPath("X", "T")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+			_, _, err = ch.ShortestPath("S", "X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		})
+
+		It("Given a contraction hierarchy, when update edge weight with negative weight or non-existed edge, then get an error and nothing changes.", func() {
+			ch, _ := graph.BuildContractionHierarchy()
+
+			err := ch.UpdateEdgeWeight("S", "T", -1)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(graph.GetEdgeWeight("S", "T")).Should(BeEquivalentTo(1))
+			err = ch.UpdateEdgeWeight("T", "S", 1)
+			Expect(errors.Is(err, ErrEdgeNotFound)).Should(BeTrue())
+			err = ch.UpdateEdgeWeight("X", "S", 1)
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		})
+
+		It("Given a contraction hierarchy, when query an unreachable vertex or the source itself, then get +Inf or 0.", func() {
+			ch, _ := graph.BuildContractionHierarchy()
+
+			dist, path, err := ch.ShortestPath("T", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+
+			dist, path, err = ch.ShortestPath("S", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeZero())
+			Expect(path).Should(Equal([]ID{"S"}))
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(3))
+			graph = NewGraph()
+			graph.SetDeterministic(true)
+			for i := 0; i < 120; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 120; i++ {
+				for j := 0; j < 3; j++ {
+					graph.AddEdge(i, random.Intn(120), float64(random.Intn(20)), nil)
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when query the contraction hierarchy, then get the same distances as dijkstra and the unpacked paths.", func() {
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+			expectSameAsDijkstra(graph, ch)
+		})
+
+		It("Given a contraction hierarchy, when update edge weights through it, then get the same distances as dijkstra on the updated graph.", func() {
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			random := rand.New(rand.NewSource(4))
+			for i := 0; i < 20; i++ {
+				from := random.Intn(120)
+				for to := range graph.egress[from] {
+					Expect(ch.UpdateEdgeWeight(from, to, float64(random.Intn(40)))).Should(Succeed())
+					break
+				}
+			}
+			expectSameAsDijkstra(graph, ch)
+		})
+
+		It("Given a contraction hierarchy, when update edge weights through the graph or a rolled back batch, then get the same distances as dijkstra on the updated graph.", func() {
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			random := rand.New(rand.NewSource(5))
+			for i := 0; i < 20; i++ {
+				from := random.Intn(120)
+				for to := range graph.egress[from] {
+					Expect(graph.UpdateEdgeWeight(from, to, float64(random.Intn(40)))).Should(Succeed())
+					break
+				}
+			}
+			expectSameAsDijkstra(graph, ch)
+
+			graph.Batch(func(tx *Tx) error {
+				for to := range graph.egress[0] {
+					tx.UpdateEdgeWeight(0, to, 1000)
+				}
+				return errors.New("rollback")
+			})
+			expectSameAsDijkstra(graph, ch)
+		})
+
+		It("Given a contraction hierarchy, when change the graph other than the edge weights, then the queries get ErrStale until it is rebuilt.", func() {
+			ch, err := graph.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			graph.AddEdge(0, 119, 0, nil)
+			dist, path, err := ch.ShortestPath(0, 119)
+			Expect(errors.Is(err, ErrStale)).Should(BeTrue())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+			graph.UpdateEdgeWeight(0, 119, 1)
+			_, _, err = ch.ShortestPath(0, 119)
+			Expect(errors.Is(err, ErrStale)).Should(BeTrue())
+
+			Expect(ch.Rebuild()).Should(Succeed())
+			expectSameAsDijkstra(graph, ch)
+
+			graph.UpdateEdgeWeight(0, 119, -1)
+			_, _, err = ch.ShortestPath(0, 119)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(errors.Is(ch.Rebuild(), ErrNegativeWeight)).Should(BeTrue())
+			graph.UpdateEdgeWeight(0, 119, 1)
+			Expect(ch.Rebuild()).Should(Succeed())
+
+			graph.DeleteVertex(119)
+			_, _, err = ch.ShortestPath(0, 1)
+			Expect(errors.Is(err, ErrStale)).Should(BeTrue())
+		})
+
+		It("Given a closed contraction hierarchy, when change the graph, then it is not notified any more.", func() {
+			ch, _ := graph.BuildContractionHierarchy()
+			Expect(graph.listeners).Should(HaveLen(1))
+			ch.Close()
+			Expect(graph.listeners).Should(BeEmpty())
+
+			graph.AddVertex("X", nil)
+			_, _, err := ch.ShortestPath(0, 1)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("Given a deterministic graph, when build the contraction hierarchy twice and update them alike, then get the same order, shortcuts and paths.", func() {
+			build := func() *ContractionHierarchy {
+				ch, err := graph.Clone(false).BuildContractionHierarchy()
+				Expect(err).ShouldNot(HaveOccurred())
+				random := rand.New(rand.NewSource(6))
+				for i := 0; i < 20; i++ {
+					from := random.Intn(120)
+					for _, to := range ch.graph.egressIDs(from) {
+						Expect(ch.UpdateEdgeWeight(from, to, float64(random.Intn(40)))).Should(Succeed())
+						break
+					}
+				}
+				return ch
+			}
+			ch1, ch2 := build(), build()
+			Expect(ch1.order).Should(Equal(ch2.order))
+			Expect(ch1.shortcuts).Should(Equal(ch2.shortcuts))
+			for source := 0; source < 120; source += 7 {
+				for destination := 0; destination < 120; destination++ {
+					dist1, path1, _ := ch1.ShortestPath(source, destination)
+					dist2, path2, _ := ch2.ShortestPath(source, destination)
+					Expect(dist1).Should(Equal(dist2))
+					Expect(path1).Should(Equal(path2))
+				}
+			}
+		})
+
+		It("Given a road-like grid, when update an edge, then only a few vertices are contracted again.", func() {
+			grid := newGridGraph(15)
+			grid.SetDeterministic(true)
+			ch, err := grid.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// the vertices contracted again lose the mark among the edges they witnessed.
+			mark := [2]int32{-1, -1}
+			random := rand.New(rand.NewSource(7))
+			for i := 0; i < 20; i++ {
+				for v := range ch.witnessed {
+					ch.witnessed[v] = append(ch.witnessed[v], mark)
+				}
+				from := random.Intn(225)
+				for _, to := range grid.egressIDs(from) {
+					Expect(ch.UpdateEdgeWeight(from, to, float64(1+random.Intn(200)))).Should(Succeed())
+					break
+				}
+				contracted := 0
+				for v, arcs := range ch.witnessed {
+					if len(arcs) == 0 || arcs[len(arcs)-1] != mark {
+						contracted++
+					} else {
+						ch.witnessed[v] = arcs[:len(arcs)-1]
+					}
+				}
+				Expect(contracted).Should(BeNumerically("<", 45))
+			}
+			expectSameAsDijkstra(grid, ch)
+		})
+
+		It("Given a road-like grid, when update an edge to be much heavier, then the shortcuts through it are repaired.", func() {
+			grid := NewGraph()
+			for i := 0; i < 25; i++ {
+				grid.AddVertex(i, nil)
+			}
+			for i := 0; i < 25; i++ {
+				if i%5 != 4 {
+					grid.AddEdge(i, i+1, 1, nil)
+					grid.AddEdge(i+1, i, 1, nil)
+				}
+				if i < 20 {
+					grid.AddEdge(i, i+5, 1, nil)
+					grid.AddEdge(i+5, i, 1, nil)
+				}
+			}
+			ch, err := grid.BuildContractionHierarchy()
+			Expect(err).ShouldNot(HaveOccurred())
+			expectSameAsDijkstra(grid, ch)
+
+			for i := 0; i < 25; i++ {
+				for to := range grid.egress[i] {
+					Expect(ch.UpdateEdgeWeight(i, to, 100)).Should(Succeed())
+					expectSameAsDijkstra(grid, ch)
+					Expect(ch.UpdateEdgeWeight(i, to, 1)).Should(Succeed())
+				}
+			}
+			expectSameAsDijkstra(grid, ch)
+		})
+	})
+})
+
+func newGridGraph(size int) *Graph {
+	random := rand.New(rand.NewSource(1))
+	graph := NewGraph()
+	for i := 0; i < size*size; i++ {
+		graph.AddVertex(i, nil)
+	}
+	for i := 0; i < size*size; i++ {
+		if i%size != size-1 {
+			graph.AddEdge(i, i+1, float64(1+random.Intn(100)), nil)
+			graph.AddEdge(i+1, i, float64(1+random.Intn(100)), nil)
+		}
+		if i < size*(size-1) {
+			graph.AddEdge(i, i+size, float64(1+random.Intn(100)), nil)
+			graph.AddEdge(i+size, i, float64(1+random.Intn(100)), nil)
+		}
+	}
+
+	return graph
+}
+
+func BenchmarkContractionHierarchy(b *testing.B) {
+	ch, _ := newGridGraph(100).BuildContractionHierarchy()
+	random := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.ShortestPath(random.Intn(10000), random.Intn(10000))
+	}
+}
+
+func BenchmarkContractionHierarchyDijkstra(b *testing.B) {
+	compact := newGridGraph(100).Freeze().graph.snapshot
+	random := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compact.dijkstra(context.Background(), int32(random.Intn(10000)), int32(random.Intn(10000)), nil, nil)
+	}
+}
+
+func BenchmarkBuildContractionHierarchy(b *testing.B) {
+	graph := newGridGraph(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.BuildContractionHierarchy()
+	}
+}
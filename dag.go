@@ -0,0 +1,202 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+)
+
+// Schedule is the result of the critical path method, in which each vertex is an event and each edge is the duration between two events.
+type Schedule struct {
+	// Earliest is the earliest start time of each vertex, 0 for the vertices without predecessors.
+	Earliest map[ID]float64
+	// Latest is the latest start time of each vertex without delaying the whole schedule.
+	Latest map[ID]float64
+	// Slack is the latest start time minus the earliest start time of each vertex.
+	Slack map[ID]float64
+	// Length is the earliest finish time of the whole schedule.
+	Length float64
+	// Critical is the longest chain of vertices, any delay of which delays the whole schedule.
+	Critical []ID
+}
+
+// DAGShortestPaths gets the shortest path from one vertex to all other vertices in a directed acyclic graph in linear time.
+// Negative weights are allowed. It gets a CycleError with ErrCycle if the graph is not acyclic.
+// https://en.wikipedia.org/wiki/Topological_sorting#Application_to_shortest_path_finding
+func (graph *Graph) DAGShortestPaths(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return graph.dagPaths(source, math.Inf(1), func(a, b float64) bool { return a < b })
+}
+
+// DAGLongestPaths gets the longest path from one vertex to all other vertices in a directed acyclic graph in linear time.
+// Negative weights are allowed and the distance of an unreachable vertex is -Inf. It gets a CycleError with ErrCycle if the graph is not acyclic.
+// https://en.wikipedia.org/wiki/Longest_path_problem#Acyclic_graphs
+func (graph *Graph) DAGLongestPaths(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return graph.dagPaths(source, math.Inf(-1), func(a, b float64) bool { return a > b })
+}
+
+func (graph *Graph) dagPaths(source ID, unreachable float64, better func(a, b float64) bool) (dist map[ID]float64, prev map[ID]ID, err error) {
+	compact := graph.compact()
+	start, err := compact.vertexIndex(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	order, err := compact.topologicalOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	distances := make([]float64, len(compact.ids))
+	for i := range distances {
+		distances[i] = unreachable
+	}
+	distances[start] = 0
+	previous := compact.relaxInOrder(order, distances, better)
+
+	return compact.distMap(distances), compact.prevMap(previous), nil
+}
+
+// CriticalPath gets the schedule of a directed acyclic graph by the critical path method in linear time.
+// It gets a CycleError with ErrCycle if the graph is not acyclic.
+// https://en.wikipedia.org/wiki/Critical_path_method
+func (graph *Graph) CriticalPath() (*Schedule, error) {
+	compact := graph.compact()
+	order, err := compact.topologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	hasPrev := make([]bool, len(compact.ids))
+	for _, target := range compact.targets {
+		hasPrev[target] = true
+	}
+	earliest := make([]float64, len(compact.ids))
+	for i := range earliest {
+		if hasPrev[i] {
+			earliest[i] = math.Inf(-1)
+		}
+	}
+	previous := compact.relaxInOrder(order, earliest, func(a, b float64) bool { return a > b })
+
+	schedule := &Schedule{
+		Earliest: make(map[ID]float64, len(compact.ids)),
+		Latest:   make(map[ID]float64, len(compact.ids)),
+		Slack:    make(map[ID]float64, len(compact.ids)),
+	}
+	if len(order) == 0 {
+		return schedule, nil
+	}
+
+	last := order[0]
+	for _, each := range order {
+		if earliest[each] > earliest[last] {
+			last = each
+		}
+	}
+	schedule.Length = earliest[last]
+
+	latest := make([]float64, len(compact.ids))
+	for i := len(order) - 1; i >= 0; i-- {
+		from := order[i]
+		latest[from] = schedule.Length
+		if compact.offsets[from] == compact.offsets[from+1] {
+			continue
+		}
+		latest[from] = math.Inf(1)
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			latest[from] = math.Min(latest[from], latest[compact.targets[e]]-compact.weights[e])
+		}
+	}
+
+	for i, id := range compact.ids {
+		schedule.Earliest[id] = earliest[i]
+		schedule.Latest[id] = latest[i]
+		schedule.Slack[id] = latest[i] - earliest[i]
+	}
+	schedule.Critical = compact.getPath(previous, last)
+	if schedule.Critical == nil {
+		schedule.Critical = []ID{compact.ids[last]}
+	}
+
+	return schedule, nil
+}
+
+// topologicalOrder gets the vertices in topological order by Kahn's algorithm, or a CycleError if the graph has a cycle.
+// https://en.wikipedia.org/wiki/Topological_sorting#Kahn's_algorithm
+func (compact *compactGraph) topologicalOrder() ([]int32, error) {
+	degrees := make([]int, len(compact.ids))
+	for _, target := range compact.targets {
+		degrees[target]++
+	}
+
+	order := make([]int32, 0, len(compact.ids))
+	for i, degree := range degrees {
+		if degree == 0 {
+			order = append(order, int32(i))
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		from := order[i]
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			if degrees[compact.targets[e]]--; degrees[compact.targets[e]] == 0 {
+				order = append(order, compact.targets[e])
+			}
+		}
+	}
+	if len(order) == len(compact.ids) {
+		return order, nil
+	}
+
+	// every vertex left has a predecessor left, so walking back along them must run into a cycle.
+	prev := make([]int32, len(compact.ids))
+	for from := range compact.ids {
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			if degrees[from] > 0 && degrees[compact.targets[e]] > 0 {
+				prev[compact.targets[e]] = int32(from)
+			}
+		}
+	}
+	var vertex int32
+	for degrees[vertex] == 0 {
+		vertex++
+	}
+	step := make([]int, len(compact.ids))
+	var walk []int32
+	for step[vertex] == 0 {
+		walk = append(walk, vertex)
+		step[vertex] = len(walk)
+		vertex = prev[vertex]
+	}
+	walk = walk[step[vertex]-1:]
+
+	cycle := make([]ID, len(walk)+1)
+	for i, each := range walk {
+		cycle[len(walk)-i] = compact.ids[each]
+	}
+	cycle[0] = compact.ids[walk[0]]
+
+	return nil, &CycleError{ErrCycle, cycle}
+}
+
+// relaxInOrder relaxes the edges from the vertices in the order, and gets the previous vertices of the better distances.
+// The vertices with infinite distances are skipped.
+func (compact *compactGraph) relaxInOrder(order []int32, dist []float64, better func(a, b float64) bool) []int32 {
+	prev := make([]int32, len(compact.ids))
+	for i := range prev {
+		prev[i] = -1
+	}
+
+	for _, from := range order {
+		if math.IsInf(dist[from], 0) {
+			continue
+		}
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			to := compact.targets[e]
+			if weight := dist[from] + compact.weights[e]; better(weight, dist[to]) {
+				dist[to] = weight
+				prev[to] = from
+			}
+		}
+	}
+
+	return prev
+}
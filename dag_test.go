@@ -0,0 +1,124 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of DAG paths and CriticalPath", func() {
+	var (
+		graph *Graph
+	)
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.SetDeterministic(true)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("B", nil)
+			graph.AddVertex("C", nil)
+			graph.AddVertex("D", nil)
+			graph.AddEdge("A", "B", 1, nil)
+			graph.AddEdge("B", "C", 1, nil)
+			graph.AddEdge("C", "D", 1, nil)
+			graph.AddEdge("D", "B", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call dag shortest paths api with non-existed vertex, then get ErrVertexNotFound.", func() {
+			dist, prev, err := graph.DAGShortestPaths("X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+		})
+
+		It("Given a graph with a cycle, when call dag apis, then get ErrCycle with the cycle.", func() {
+			var cycleErr *CycleError
+
+			_, _, err := graph.DAGShortestPaths("A")
+			Expect(errors.As(err, &cycleErr)).Should(BeTrue())
+			Expect(cycleErr.Err).Should(Equal(ErrCycle))
+			Expect(cycleErr.Cycle).Should(HaveLen(4))
+			Expect(cycleErr.Cycle[0]).Should(Equal(cycleErr.Cycle[3]))
+			Expect(cycleErr.Cycle[:3]).Should(ConsistOf("B", "C", "D"))
+			for i := 1; i < len(cycleErr.Cycle); i++ {
+				_, err := graph.GetEdge(cycleErr.Cycle[i-1], cycleErr.Cycle[i])
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			_, _, err = graph.DAGLongestPaths("A")
+			Expect(errors.Is(err, ErrCycle)).Should(BeTrue())
+			schedule, err := graph.CriticalPath()
+			Expect(errors.Is(err, ErrCycle)).Should(BeTrue())
+			Expect(schedule).Should(BeNil())
+		})
+
+		It("Given a graph with the edge in the cycle disabled, when call dag shortest paths api, then the cycle is ignored.", func() {
+			graph.DisableEdge("D", "B")
+			dist, _, err := graph.DAGShortestPaths("A")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"A": 0, "B": 1, "C": 2, "D": 3}))
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.SetDeterministic(true)
+			for _, id := range []string{"S", "A", "B", "C", "T", "X"} {
+				graph.AddVertex(id, nil)
+			}
+			graph.AddEdge("S", "A", 3, nil)
+			graph.AddEdge("S", "B", 2, nil)
+			graph.AddEdge("A", "C", -4, nil)
+			graph.AddEdge("B", "C", 1, nil)
+			graph.AddEdge("C", "T", 2, nil)
+			graph.AddEdge("A", "T", 5, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a dag with negative weights, when call dag shortest paths api, then get the shortest paths.", func() {
+			dist, prev, err := graph.DAGShortestPaths("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "A": 3, "B": 2, "C": -1, "T": 1, "X": math.Inf(1)}))
+			Expect(prev).Should(Equal(map[ID]ID{"S": nil, "A": "S", "B": "S", "C": "A", "T": "C", "X": nil}))
+		})
+
+		It("Given a dag, when call dag longest paths api, then get the longest paths.", func() {
+			dist, prev, err := graph.DAGLongestPaths("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "A": 3, "B": 2, "C": 3, "T": 8, "X": math.Inf(-1)}))
+			Expect(prev).Should(Equal(map[ID]ID{"S": nil, "A": "S", "B": "S", "C": "B", "T": "A", "X": nil}))
+		})
+
+		It("Given a dag of tasks, when call critical path api, then get the schedule and the critical chain.", func() {
+			graph.UpdateEdgeWeight("A", "C", 4)
+
+			schedule, err := graph.CriticalPath()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(schedule.Length).Should(BeEquivalentTo(9))
+			Expect(schedule.Critical).Should(Equal([]ID{"S", "A", "C", "T"}))
+			Expect(schedule.Earliest).Should(Equal(map[ID]float64{"S": 0, "A": 3, "B": 2, "C": 7, "T": 9, "X": 0}))
+			Expect(schedule.Latest).Should(Equal(map[ID]float64{"S": 0, "A": 3, "B": 6, "C": 7, "T": 9, "X": 9}))
+			Expect(schedule.Slack).Should(Equal(map[ID]float64{"S": 0, "A": 0, "B": 4, "C": 0, "T": 0, "X": 9}))
+		})
+
+		It("Given an empty graph, when call critical path api, then get an empty schedule.", func() {
+			schedule, err := NewGraph().CriticalPath()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(schedule.Length).Should(BeZero())
+			Expect(schedule.Critical).Should(BeNil())
+			Expect(schedule.Earliest).Should(BeEmpty())
+		})
+	})
+})
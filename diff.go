@@ -0,0 +1,241 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"reflect"
+)
+
+// GraphDiff is the changes to turn a graph into another, which can be encoded by encoding/json to ship between services.
+// The ids and values are decoded from JSON as the generic JSON values, e.g. the numbers as float64,
+// so ConvertIDs is needed to apply the decoded diff to a graph of other ids. The infinite weights cannot be encoded. The removed edges include the edges of the removed vertices.
+type GraphDiff struct {
+	AddedVertices   []VertexDiff `json:"addedVertices,omitempty"`
+	RemovedVertices []VertexDiff `json:"removedVertices,omitempty"`
+	AddedEdges      []EdgeDiff   `json:"addedEdges,omitempty"`
+	RemovedEdges    []EdgeDiff   `json:"removedEdges,omitempty"`
+	WeightChanges   []WeightDiff `json:"weightChanges,omitempty"`
+}
+
+// VertexDiff is a vertex added or removed with its value.
+type VertexDiff struct {
+	ID    ID          `json:"id"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// EdgeDiff is an edge added or removed with its weight and value.
+type EdgeDiff struct {
+	From   ID          `json:"from"`
+	To     ID          `json:"to"`
+	Weight float64     `json:"weight"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+// WeightDiff is the weight of an edge changed from the old one to the new one.
+type WeightDiff struct {
+	From ID      `json:"from"`
+	To   ID      `json:"to"`
+	Old  float64 `json:"old"`
+	New  float64 `json:"new"`
+}
+
+// Empty reports whether there is no change in the diff.
+func (diff GraphDiff) Empty() bool {
+	return len(diff.AddedVertices) == 0 && len(diff.RemovedVertices) == 0 && len(diff.AddedEdges) == 0 &&
+		len(diff.RemovedEdges) == 0 && len(diff.WeightChanges) == 0
+}
+
+// ConvertIDs gets a copy of the diff with all the ids converted by the function, e.g. the numbers decoded from JSON back to int.
+// It stops with the error of the function if any.
+func (diff GraphDiff) ConvertIDs(convert func(id ID) (ID, error)) (GraphDiff, error) {
+	var converted GraphDiff
+	var err error
+	convertVertices := func(vertices []VertexDiff) []VertexDiff {
+		var result []VertexDiff
+		for _, each := range vertices {
+			if err == nil {
+				each.ID, err = convert(each.ID)
+			}
+			result = append(result, each)
+		}
+		return result
+	}
+	convertEdge := func(from, to ID) (ID, ID) {
+		if err == nil {
+			from, err = convert(from)
+		}
+		if err == nil {
+			to, err = convert(to)
+		}
+		return from, to
+	}
+
+	converted.AddedVertices = convertVertices(diff.AddedVertices)
+	converted.RemovedVertices = convertVertices(diff.RemovedVertices)
+	for _, each := range diff.AddedEdges {
+		each.From, each.To = convertEdge(each.From, each.To)
+		converted.AddedEdges = append(converted.AddedEdges, each)
+	}
+	for _, each := range diff.RemovedEdges {
+		each.From, each.To = convertEdge(each.From, each.To)
+		converted.RemovedEdges = append(converted.RemovedEdges, each)
+	}
+	for _, each := range diff.WeightChanges {
+		each.From, each.To = convertEdge(each.From, each.To)
+		converted.WeightChanges = append(converted.WeightChanges, each)
+	}
+	if err != nil {
+		return GraphDiff{}, err
+	}
+
+	return converted, nil
+}
+
+// Diff gets the changes from graph a to graph b, the weights are compared with the tolerance of graph b.
+// The values of the vertices and edges are carried along but not compared, and the disabled ones are compared as the enabled ones.
+// The changes are in the stable order of the graphs in the deterministic mode.
+func Diff(a, b *Graph) GraphDiff {
+	var diff GraphDiff
+	for _, id := range a.vertexIDs() {
+		if _, exists := b.vertices[id]; !exists {
+			diff.RemovedVertices = append(diff.RemovedVertices, VertexDiff{id, a.vertices[id].self})
+		}
+	}
+	for _, id := range b.vertexIDs() {
+		if _, exists := a.vertices[id]; !exists {
+			diff.AddedVertices = append(diff.AddedVertices, VertexDiff{id, b.vertices[id].self})
+		}
+	}
+
+	for _, from := range a.vertexIDs() {
+		for _, to := range a.egressIDs(from) {
+			if _, exists := a.vertices[to]; !exists {
+				continue
+			}
+			old := a.egress[from][to]
+			if !b.hasEdge(from, to) {
+				diff.RemovedEdges = append(diff.RemovedEdges, EdgeDiff{from, to, old.weight, old.self})
+			} else if weight := b.egress[from][to].weight; !b.equalWeights(old.weight, weight) {
+				diff.WeightChanges = append(diff.WeightChanges, WeightDiff{from, to, old.weight, weight})
+			}
+		}
+	}
+	for _, from := range b.vertexIDs() {
+		for _, to := range b.egressIDs(from) {
+			if _, exists := b.vertices[to]; !exists || a.hasEdge(from, to) {
+				continue
+			}
+			each := b.egress[from][to]
+			diff.AddedEdges = append(diff.AddedEdges, EdgeDiff{from, to, each.weight, each.self})
+		}
+	}
+
+	return diff
+}
+
+// Apply applies the diff to the graph atomically, nothing is changed if any change does not apply.
+// The edges and vertices are removed first, then the vertices and edges are added, and the weights are changed at last.
+// The old weights of the weight changes are not checked.
+// Try to remove a vertex or an edge not in the graph will get an error.
+// Try to add a vertex or an edge already in the graph will get an error.
+// Try to change the weight of an edge not in the graph will get an error.
+// Try to apply an id of the type no vertex of a non-empty graph has will get ErrIDType,
+// e.g. the numbers decoded from JSON applied to a graph of int ids.
+func (graph *Graph) Apply(diff GraphDiff) error {
+	if err := graph.checkIDTypes(diff); err != nil {
+		return err
+	}
+
+	return graph.Batch(func(tx *Tx) error {
+		for _, each := range diff.RemovedEdges {
+			if !graph.hasEdge(each.From, each.To) {
+				return &EdgeError{ErrEdgeNotFound, each.From, each.To}
+			}
+			tx.DeleteEdge(each.From, each.To)
+		}
+		for _, each := range diff.RemovedVertices {
+			if _, exists := graph.vertices[each.ID]; !exists {
+				return &VertexError{ErrVertexNotFound, each.ID}
+			}
+			tx.DeleteVertex(each.ID)
+		}
+		for _, each := range diff.AddedVertices {
+			if err := tx.AddVertex(each.ID, each.Value); err != nil {
+				return err
+			}
+		}
+		for _, each := range diff.AddedEdges {
+			if err := tx.AddEdge(each.From, each.To, each.Weight, each.Value); err != nil {
+				return err
+			}
+		}
+		for _, each := range diff.WeightChanges {
+			if err := tx.UpdateEdgeWeight(each.From, each.To, each.New); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// checkIDTypes checks the types of the ids in the diff not in the graph, the types of the vertices are collected only if there is any.
+func (graph *Graph) checkIDTypes(diff GraphDiff) error {
+	var types map[reflect.Type]bool
+	check := func(id ID) error {
+		if _, exists := graph.vertices[id]; exists || len(graph.vertices) == 0 {
+			return nil
+		}
+		if types == nil {
+			types = make(map[reflect.Type]bool)
+			for each := range graph.vertices {
+				types[reflect.TypeOf(each)] = true
+			}
+		}
+		if !types[reflect.TypeOf(id)] {
+			return &VertexError{ErrIDType, id}
+		}
+		return nil
+	}
+
+	for _, vertices := range [][]VertexDiff{diff.AddedVertices, diff.RemovedVertices} {
+		for _, each := range vertices {
+			if err := check(each.ID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, edges := range [][]EdgeDiff{diff.AddedEdges, diff.RemovedEdges} {
+		for _, each := range edges {
+			if err := check(each.From); err != nil {
+				return err
+			}
+			if err := check(each.To); err != nil {
+				return err
+			}
+		}
+	}
+	for _, each := range diff.WeightChanges {
+		if err := check(each.From); err != nil {
+			return err
+		}
+		if err := check(each.To); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasEdge reports whether there is an edge between the two vertices in the graph.
+func (graph *Graph) hasEdge(from, to ID) bool {
+	if _, exists := graph.vertices[from]; !exists {
+		return false
+	}
+	if _, exists := graph.vertices[to]; !exists {
+		return false
+	}
+	_, exists := graph.egress[from][to]
+
+	return exists
+}
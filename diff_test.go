@@ -0,0 +1,176 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of diff", func() {
+	var (
+		yesterday *Graph
+		today     *Graph
+	)
+
+	BeforeEach(func() {
+		yesterday = NewGraph()
+		yesterday.SetDeterministic(true)
+		yesterday.AddVertex("S", "S")
+		yesterday.AddVertex("A", "A")
+		yesterday.AddVertex("B", "B")
+		yesterday.AddVertex("T", "T")
+		yesterday.AddEdge("S", "A", 1, "SA")
+		yesterday.AddEdge("A", "T", 1, "AT")
+		yesterday.AddEdge("S", "B", 2, "SB")
+		yesterday.AddEdge("B", "T", 2, "BT")
+		yesterday.AddEdge("S", "T", 5, "ST")
+
+		today = NewGraph()
+		today.SetDeterministic(true)
+		today.AddVertex("S", "S")
+		today.AddVertex("A", "A")
+		today.AddVertex("T", "T")
+		today.AddVertex("C", "C")
+		today.AddEdge("S", "A", 1, "SA")
+		today.AddEdge("A", "T", 3, "AT")
+		today.AddEdge("S", "C", 1, "SC")
+		today.AddEdge("C", "T", 1, "CT")
+		today.AddEdge("T", "S", 1, "TS")
+	})
+
+	AfterEach(func() {
+		yesterday = nil
+		today = nil
+	})
+
+	Context("exception test", func() {
+		It("Given a diff not matching the graph, when apply it, then get an error and nothing is changed.", func() {
+			diff := Diff(yesterday, today)
+			today.EnableJournal()
+			err := today.Apply(diff)
+			Expect(err).Should(HaveOccurred())
+			Expect(Diff(yesterday, today).Empty()).Should(BeFalse())
+			Expect(Diff(today, today).Empty()).Should(BeTrue())
+			Expect(today.Undo()).Should(BeFalse())
+
+			Expect(errors.Is(yesterday.Apply(GraphDiff{RemovedVertices: []VertexDiff{{ID: "X"}}}), ErrVertexNotFound)).Should(BeTrue())
+			Expect(errors.Is(yesterday.Apply(GraphDiff{RemovedEdges: []EdgeDiff{{From: "T", To: "S"}}}), ErrEdgeNotFound)).Should(BeTrue())
+			Expect(errors.Is(yesterday.Apply(GraphDiff{AddedVertices: []VertexDiff{{ID: "S"}}}), ErrDuplicateVertex)).Should(BeTrue())
+			Expect(errors.Is(yesterday.Apply(GraphDiff{WeightChanges: []WeightDiff{{From: "T", To: "S", Old: 1, New: 2}}}), ErrEdgeNotFound)).Should(BeTrue())
+		})
+
+		It("Given a diff of int ids decoded from JSON, when apply it without converting the ids, then get ErrIDType and nothing is changed.", func() {
+			a, b := NewGraph(), NewGraph()
+			for i := 0; i < 3; i++ {
+				a.AddVertex(i, nil)
+				b.AddVertex(i, nil)
+			}
+			b.AddVertex(3, nil)
+			b.AddEdge(0, 3, 1, nil)
+			encoded, _ := json.Marshal(Diff(a, b))
+			var decoded GraphDiff
+			Expect(json.Unmarshal(encoded, &decoded)).Should(Succeed())
+
+			err := a.Apply(decoded)
+			Expect(errors.Is(err, ErrIDType)).Should(BeTrue())
+			var vertexErr *VertexError
+			Expect(errors.As(err, &vertexErr)).Should(BeTrue())
+			Expect(vertexErr.ID).Should(Equal(float64(3)))
+			Expect(a.Equal(b, EqualOptions{})).Should(BeFalse())
+			Expect(a.vertices).Should(HaveLen(3))
+
+			_, err = decoded.ConvertIDs(func(id ID) (ID, error) {
+				return nil, fmt.Errorf("unknown id %v", id)
+			})
+			Expect(err).Should(MatchError("unknown id 3"))
+		})
+
+		It("Given a diff with infinite weights, when encode it to JSON, then get an error.", func() {
+			today.UpdateEdgeWeight("A", "T", math.Inf(1))
+			_, err := json.Marshal(Diff(yesterday, today))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given two graphs, when diff them, then get the changes in stable order.", func() {
+			diff := Diff(yesterday, today)
+			Expect(diff.AddedVertices).Should(Equal([]VertexDiff{{"C", "C"}}))
+			Expect(diff.RemovedVertices).Should(Equal([]VertexDiff{{"B", "B"}}))
+			Expect(diff.AddedEdges).Should(Equal([]EdgeDiff{{"S", "C", 1, "SC"}, {"T", "S", 1, "TS"}, {"C", "T", 1, "CT"}}))
+			Expect(diff.RemovedEdges).Should(Equal([]EdgeDiff{{"S", "B", 2, "SB"}, {"S", "T", 5, "ST"}, {"B", "T", 2, "BT"}}))
+			Expect(diff.WeightChanges).Should(Equal([]WeightDiff{{"A", "T", 1, 3}}))
+		})
+
+		It("Given two graphs with the weights within the tolerance, when diff them, then the weights are not changed.", func() {
+			today = NewGraph()
+			today.SetTolerance(0.1)
+			today.AddVertex("S", nil)
+			today.AddVertex("T", nil)
+			today.AddEdge("S", "T", 5.05, nil)
+			yesterday = NewGraph()
+			yesterday.AddVertex("S", nil)
+			yesterday.AddVertex("T", nil)
+			yesterday.AddEdge("S", "T", 5, nil)
+			Expect(Diff(yesterday, today).Empty()).Should(BeTrue())
+			today.UpdateEdgeWeight("S", "T", math.Inf(1))
+			Expect(Diff(yesterday, today).WeightChanges).Should(HaveLen(1))
+			Expect(Diff(today, today).Empty()).Should(BeTrue())
+		})
+
+		It("Given the diff of two graphs, when apply it to the first one, then get the second one.", func() {
+			Expect(yesterday.Apply(Diff(yesterday, today))).ShouldNot(HaveOccurred())
+			Expect(Diff(yesterday, today).Empty()).Should(BeTrue())
+			Expect(yesterday.GetEdge("C", "T")).Should(Equal("CT"))
+			Expect(yesterday.CheckIntegrity()).ShouldNot(HaveOccurred())
+		})
+
+		It("Given the diff encoded to JSON, when decode and apply it, then get the second one.", func() {
+			encoded, err := json.Marshal(Diff(yesterday, today))
+			Expect(err).ShouldNot(HaveOccurred())
+			var decoded GraphDiff
+			Expect(json.Unmarshal(encoded, &decoded)).ShouldNot(HaveOccurred())
+			Expect(decoded).Should(Equal(Diff(yesterday, today)))
+			Expect(yesterday.Apply(decoded)).ShouldNot(HaveOccurred())
+			Expect(Diff(yesterday, today).Empty()).Should(BeTrue())
+		})
+
+		It("Given the diff of int ids encoded to JSON, when decode it and convert the ids back, then get the second one by applying it.", func() {
+			a, b := NewGraph(), NewGraph()
+			a.SetDeterministic(true)
+			b.SetDeterministic(true)
+			for i := 0; i < 4; i++ {
+				a.AddVertex(i, nil)
+				b.AddVertex(i+1, nil)
+			}
+			a.AddEdge(0, 1, 1, nil)
+			a.AddEdge(1, 2, 2, nil)
+			a.AddEdge(2, 3, 3, nil)
+			b.AddEdge(1, 2, 5, nil)
+			b.AddEdge(2, 3, 3, nil)
+			b.AddEdge(3, 4, 4, nil)
+			encoded, err := json.Marshal(Diff(a, b))
+			Expect(err).ShouldNot(HaveOccurred())
+			var decoded GraphDiff
+			Expect(json.Unmarshal(encoded, &decoded)).Should(Succeed())
+
+			converted, err := decoded.ConvertIDs(func(id ID) (ID, error) {
+				return int(id.(float64)), nil
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(converted).Should(Equal(Diff(a, b)))
+			Expect(a.Apply(converted)).Should(Succeed())
+			Expect(a.Equal(b, EqualOptions{})).Should(BeTrue())
+
+			empty := NewGraph()
+			Expect(empty.Apply(GraphDiff{AddedVertices: decoded.AddedVertices})).Should(Succeed())
+			_, err = empty.GetVertex(float64(4))
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
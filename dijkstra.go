@@ -3,46 +3,94 @@
 package goraph
 
 import (
-	"fmt"
-	"github.com/starwander/GoFibonacciHeap"
-	"math"
+	"context"
 )
 
+// checkInterval is the number of vertices visited between two checks of the context.
+const checkInterval = 256
+
 // Dijkstra gets the shortest path from one vertex to all other vertices in the graph.
 // https://en.wikipedia.org/wiki/Dijkstra%27s_algorithm
 func (graph *Graph) Dijkstra(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return graph.DijkstraCtx(context.Background(), source)
+}
+
+// DijkstraCtx gets the shortest path from one vertex to all other vertices in the graph, and stops when the context is done.
+// If the context is done, the partial result is returned together with the error of the context,
+// in which the distances of the vertices not visited yet are tentative.
+func (graph *Graph) DijkstraCtx(ctx context.Context, source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return graph.dijkstraQueue(ctx, source, nil)
+}
+
+// DijkstraQueue gets the shortest path from one vertex to all other vertices in the graph with the priority queue created by the factory,
+// e.g. NewBinaryHeap, NewPairingHeap, NewRadixHeap or NewFibonacciHeap.
+func (graph *Graph) DijkstraQueue(source ID, queue QueueFactory) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return graph.dijkstraQueue(context.Background(), source, queue)
+}
+
+func (graph *Graph) dijkstraQueue(ctx context.Context, source ID, queue QueueFactory) (dist map[ID]float64, prev map[ID]ID, err error) {
+	compact := graph.compact()
+	index, err := compact.vertexIndex(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	distances, previous, err := compact.dijkstra(ctx, index, -1, nil, queue)
+	if distances == nil {
+		return nil, nil, err
+	}
+
+	return compact.distMap(distances), compact.prevMap(previous), err
+}
+
+// DijkstraWith gets the best path under the algebra from one vertex to all other vertices in the graph.
+// The weight of the source is the identity of the algebra and the weight of an unreachable vertex is the zero of the algebra.
+func (graph *Graph) DijkstraWith(source ID, algebra PathAlgebra) (dist map[ID]PathWeight, prev map[ID]ID, err error) {
+	return graph.dijkstraWith(context.Background(), source, algebra, nil)
+}
+
+// dijkstraWith calculates with the edges blocked for this calculation skipped besides the disabled ones.
+func (graph *Graph) dijkstraWith(ctx context.Context, source ID, algebra PathAlgebra, blocked *blocking) (dist map[ID]PathWeight, prev map[ID]ID, err error) {
 	if _, exists := graph.vertices[source]; !exists {
-		return nil, nil, fmt.Errorf("Vertex %v is not existed", source)
+		return nil, nil, &VertexError{ErrVertexNotFound, source}
 	}
 
-	dist = make(map[ID]float64)
+	dist = make(map[ID]PathWeight)
 	prev = make(map[ID]ID)
-	heap := fibHeap.NewFibHeap()
+	visited := make(map[ID]bool)
+	queue := newWeightQueue(algebra)
 
-	for id := range graph.vertices {
+	for _, id := range graph.vertexIDs() {
 		prev[id] = nil
 		if id != source {
-			dist[id] = math.Inf(1)
-			heap.Insert(id, math.Inf(1))
+			dist[id] = algebra.Zero()
 		} else {
-			dist[id] = 0
-			heap.Insert(id, 0)
+			dist[id] = algebra.Identity()
 		}
+		queue.insert(id, dist[id])
 	}
 
-	for heap.Num() != 0 {
-		min, _ := heap.ExtractMin()
-		for to, edge := range graph.egress[min] {
-			if edge.getWeight() < 0 {
-				return nil, nil, fmt.Errorf("Negative weight form vertex %v to vertex %v is not allowed", min, to)
+	for i := 0; queue.num() != 0; i++ {
+		if i%checkInterval == 0 && ctx.Err() != nil {
+			return dist, prev, ctx.Err()
+		}
+		min := queue.extractMin()
+		visited[min] = true
+		for _, to := range graph.egressIDs(min) {
+			edge := graph.egress[min][to]
+			weight, err := algebra.Extend(dist[min], min, to, edge.getWeight())
+			if err != nil {
+				return nil, nil, err
 			}
-			if !edge.enable {
+			if _, exists := dist[to]; !exists || !edge.enable || blocked.blocks(min, to) {
 				continue
 			}
-			if dist[min]+edge.getWeight() < dist[to] {
-				heap.DecreaseKey(to, dist[min]+edge.getWeight())
+			if algebra.Better(weight, dist[to]) {
+				queue.decreaseKey(to, weight)
+				prev[to] = min
+				dist[to] = weight
+			} else if graph.deterministic && !visited[to] && prev[to] != nil && !algebra.Better(dist[to], weight) && graph.before(min, prev[to]) {
 				prev[to] = min
-				dist[to] = dist[min] + edge.getWeight()
 			}
 		}
 	}
@@ -50,6 +98,15 @@ func (graph *Graph) Dijkstra(source ID) (dist map[ID]float64, prev map[ID]ID, er
 	return
 }
 
+func toFloatWeights(weights map[ID]PathWeight) map[ID]float64 {
+	floats := make(map[ID]float64, len(weights))
+	for id, weight := range weights {
+		floats[id] = weight.(float64)
+	}
+
+	return floats
+}
+
 func getPath(prev map[ID]ID, lastNode ID) (path []ID) {
 	prevNode := prev[lastNode]
 	if prevNode == nil {
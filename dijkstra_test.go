@@ -3,8 +3,10 @@
 package goraph
 
 import (
+	"context"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"math"
 )
 
 var _ = Describe("Tests of Dijkstra", func() {
@@ -42,6 +44,19 @@ var _ = Describe("Tests of Dijkstra", func() {
 			Expect(prev).Should(BeNil())
 			Expect(err).Should(HaveOccurred())
 		})
+
+		It("Given a cancelled context, when call dijkstra api with the context, then get the partial result and the error of the context", func() {
+			graph.AddVertexWithEdges(&myVertex{"S", map[ID]float64{"A": 10, "B": 10}, map[ID]float64{}})
+			graph.AddVertexWithEdges(&myVertex{"A", map[ID]float64{}, map[ID]float64{"S": 10, "B": 5}})
+			graph.AddVertexWithEdges(&myVertex{"B", map[ID]float64{"A": 5}, map[ID]float64{"S": 10}})
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			dist, prev, err := graph.DijkstraCtx(ctx, "S")
+			Expect(err).Should(Equal(context.Canceled))
+			Expect(dist).Should(BeEquivalentTo(map[ID]float64{"S": 0, "A": math.Inf(1), "B": math.Inf(1)}))
+			Expect(prev).Should(BeEquivalentTo(map[ID]ID{"S": nil, "A": nil, "B": nil}))
+		})
 	})
 
 	Context("algorithem test", func() {
@@ -0,0 +1,175 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"math"
+	"sort"
+)
+
+// DijkstraAll gets the shortest distance from one vertex to all other vertices in the graph,
+// together with all the predecessors of each vertex on the equal-cost shortest paths.
+// Two distances are equal if they differ no more than the tolerance of the graph from the shortest one.
+// The predecessors may form cycles through the zero-weight edges, the source has no predecessor.
+func (graph *Graph) DijkstraAll(source ID) (dist map[ID]float64, prev map[ID][]ID, err error) {
+	if _, exists := graph.vertices[source]; !exists {
+		return nil, nil, &VertexError{ErrVertexNotFound, source}
+	}
+
+	type candidate struct {
+		from ID
+		dist float64
+	}
+	dist = make(map[ID]float64)
+	prev = make(map[ID][]ID)
+	// candidates are the vertices reaching each vertex with the distances through them,
+	// which are filtered by the final distances, so that the equal ones do not depend on the order they are found.
+	candidates := make(map[ID][]candidate)
+	visited := make(map[ID]bool)
+	queue := newWeightQueue(Shortest(nil))
+
+	for _, id := range graph.vertexIDs() {
+		prev[id] = nil
+		if id != source {
+			dist[id] = math.Inf(1)
+		} else {
+			dist[id] = 0
+		}
+		queue.insert(id, dist[id])
+	}
+
+	for queue.num() != 0 {
+		min := queue.extractMin()
+		visited[min] = true
+		for _, to := range graph.egressIDs(min) {
+			edge := graph.egress[min][to]
+			if edge.getWeight() < 0 {
+				return nil, nil, &EdgeError{ErrNegativeWeight, min, to}
+			}
+			if _, exists := dist[to]; !exists || !edge.enable || to == min || to == source {
+				continue
+			}
+			weight := dist[min] + edge.getWeight()
+			if weight == math.Inf(1) {
+				continue
+			}
+			candidates[to] = append(candidates[to], candidate{min, weight})
+			if weight < dist[to] && !visited[to] {
+				queue.decreaseKey(to, weight)
+				dist[to] = weight
+			}
+		}
+	}
+
+	for to, each := range candidates {
+		for _, c := range each {
+			if c.dist-dist[to] <= graph.tolerance {
+				prev[to] = append(prev[to], c.from)
+			}
+		}
+	}
+
+	return
+}
+
+// AllShortestPaths gets all the equal-cost shortest paths between two vertex in the graph.
+// Two distances are equal if they differ no more than the tolerance of the graph from the shortest one.
+// The only path from a vertex to itself is the vertex. No path is returned if the destination is unreachable.
+func (graph *Graph) AllShortestPaths(source, destination ID) (dist float64, paths [][]ID, err error) {
+	dists, prev, err := graph.dijkstraAllTo(source, destination)
+	if err != nil {
+		return math.Inf(1), nil, err
+	}
+	if dists[destination] == math.Inf(1) {
+		return dists[destination], nil, nil
+	}
+
+	walkShortestPaths(prev, source, destination, func(suffix []ID) {
+		path := make([]ID, len(suffix))
+		for i, each := range suffix {
+			path[len(suffix)-i-1] = each
+		}
+		paths = append(paths, path)
+	})
+	if graph.deterministic {
+		sort.Slice(paths, func(i, j int) bool {
+			return graph.pathBefore(paths[i], paths[j])
+		})
+	}
+
+	return dists[destination], paths, nil
+}
+
+// CountShortestPaths gets the number of the equal-cost shortest paths between two vertex in the graph without enumerating them.
+// Two distances are equal if they differ no more than the tolerance of the graph from the shortest one.
+// The paths are enumerated only if the predecessors form cycles through the zero-weight edges, to count the loopless ones.
+func (graph *Graph) CountShortestPaths(source, destination ID) (dist float64, count int, err error) {
+	dists, prev, err := graph.dijkstraAllTo(source, destination)
+	if err != nil {
+		return math.Inf(1), 0, err
+	}
+	if dists[destination] == math.Inf(1) {
+		return dists[destination], 0, nil
+	}
+
+	counts := map[ID]int{source: 1}
+	counting := make(map[ID]bool)
+	cyclic := false
+	var countOf func(vertex ID) int
+	countOf = func(vertex ID) int {
+		if c, exists := counts[vertex]; exists {
+			return c
+		}
+		if counting[vertex] {
+			cyclic = true
+			return 0
+		}
+		counting[vertex] = true
+		c := 0
+		for _, each := range prev[vertex] {
+			c += countOf(each)
+		}
+		counting[vertex] = false
+		counts[vertex] = c
+		return c
+	}
+	count = countOf(destination)
+
+	if cyclic {
+		count = 0
+		walkShortestPaths(prev, source, destination, func(suffix []ID) {
+			count++
+		})
+	}
+
+	return dists[destination], count, nil
+}
+
+// walkShortestPaths visits the loopless paths from the destination back to the source along the predecessors.
+func walkShortestPaths(prev map[ID][]ID, source, destination ID, visit func(suffix []ID)) {
+	onPath := make(map[ID]bool)
+	var walk func(vertex ID, suffix []ID)
+	walk = func(vertex ID, suffix []ID) {
+		suffix = append(suffix, vertex)
+		if vertex == source {
+			visit(suffix)
+			return
+		}
+		onPath[vertex] = true
+		for _, each := range prev[vertex] {
+			if !onPath[each] {
+				walk(each, suffix[:len(suffix):len(suffix)])
+			}
+		}
+		onPath[vertex] = false
+	}
+	walk(destination, nil)
+}
+
+func (graph *Graph) dijkstraAllTo(source, destination ID) (dist map[ID]float64, prev map[ID][]ID, err error) {
+	if _, exists := graph.vertices[destination]; !exists {
+		return nil, nil, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	return graph.DijkstraAll(source)
+}
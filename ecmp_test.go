@@ -0,0 +1,191 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of equal-cost shortest paths", func() {
+	var (
+		graph *Graph
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.AddVertex("S", nil)
+		graph.AddVertex("A", nil)
+		graph.AddVertex("B", nil)
+		graph.AddVertex("C", nil)
+		graph.AddVertex("D", nil)
+		graph.AddVertex("T", nil)
+		graph.AddVertex("X", nil)
+		graph.AddEdge("S", "A", 1, nil)
+		graph.AddEdge("S", "B", 1, nil)
+		graph.AddEdge("A", "C", 1, nil)
+		graph.AddEdge("B", "C", 1, nil)
+		graph.AddEdge("C", "T", 1, nil)
+		graph.AddEdge("C", "D", 0.5, nil)
+		graph.AddEdge("D", "T", 0.5, nil)
+		Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	Context("exception test", func() {
+		It("Given a graph without vertex Y, when call all shortest paths api with Y, then get error", func() {
+			_, paths, err := graph.AllShortestPaths("Y", "T")
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+			_, count, err := graph.CountShortestPaths("S", "Y")
+			Expect(count).Should(BeZero())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph with negative edge, when call all shortest paths api, then get error", func() {
+			graph.UpdateEdgeWeight("D", "T", -1)
+			dist, prev, err := graph.DijkstraAll("S")
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+			_, paths, err := graph.AllShortestPaths("S", "T")
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given a graph with equal-cost paths, when call dijkstra all api, then get all the predecessors on the shortest paths.", func() {
+			dist, prev, err := graph.DijkstraAll("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["T"]).Should(BeEquivalentTo(3))
+			Expect(prev["S"]).Should(BeEmpty())
+			Expect(prev["A"]).Should(ConsistOf(ID("S")))
+			Expect(prev["C"]).Should(ConsistOf(ID("A"), ID("B")))
+			Expect(prev["T"]).Should(ConsistOf(ID("C"), ID("D")))
+			Expect(prev["X"]).Should(BeEmpty())
+		})
+
+		It("Given a graph with equal-cost paths, when call all shortest paths api, then get all of them.", func() {
+			dist, paths, err := graph.AllShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(3))
+			Expect(paths).Should(ConsistOf(
+				[]ID{"S", "A", "C", "T"},
+				[]ID{"S", "B", "C", "T"},
+				[]ID{"S", "A", "C", "D", "T"},
+				[]ID{"S", "B", "C", "D", "T"},
+			))
+
+			dist, count, err := graph.CountShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(3))
+			Expect(count).Should(Equal(4))
+		})
+
+		It("Given a graph with zero-weight cycle, when call all shortest paths api, then get all the loopless paths through the cycle.", func() {
+			graph.AddEdge("A", "B", 0, nil)
+			graph.AddEdge("B", "A", 0, nil)
+
+			_, paths, err := graph.AllShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(paths).Should(HaveLen(8))
+			for _, path := range paths {
+				seen := make(map[ID]bool)
+				for _, vertex := range path {
+					Expect(seen[vertex]).Should(BeFalse(), "%v", path)
+					seen[vertex] = true
+				}
+				Expect(graph.GetPathWeight(path)).Should(BeEquivalentTo(3))
+			}
+			_, count, err := graph.CountShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(count).Should(Equal(8))
+		})
+
+		It("Given an equal-cost predecessor through a zero-weight edge, when call all shortest paths api in any order of the vertices, then it is always found.", func() {
+			for _, order := range [][]ID{{"S", "A", "B", "T"}, {"S", "B", "A", "T"}, {"T", "A", "B", "S"}, {"T", "B", "A", "S"}} {
+				graph = NewGraph()
+				graph.SetDeterministic(true)
+				for _, id := range order {
+					graph.AddVertex(id, nil)
+				}
+				graph.AddEdge("S", "A", 1, nil)
+				graph.AddEdge("S", "B", 1, nil)
+				graph.AddEdge("B", "A", 0, nil)
+				graph.AddEdge("A", "T", 1, nil)
+
+				_, prev, err := graph.DijkstraAll("S")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(prev["A"]).Should(ConsistOf(ID("S"), ID("B")), "%v", order)
+				_, paths, _ := graph.AllShortestPaths("S", "T")
+				Expect(paths).Should(ConsistOf([]ID{"S", "A", "T"}, []ID{"S", "B", "A", "T"}), "%v", order)
+				_, count, _ := graph.CountShortestPaths("S", "T")
+				Expect(count).Should(Equal(2), "%v", order)
+			}
+		})
+
+		It("Given a chain of distances each within the tolerance of the next, when call all shortest paths api, then only the ones within the tolerance of the shortest are equal.", func() {
+			graph = NewGraph()
+			graph.SetTolerance(0.5)
+			for _, id := range []ID{"S", "A", "B", "C", "T"} {
+				graph.AddVertex(id, nil)
+			}
+			// T is reached through A, B and C in turn, each 0.4 shorter than the one before.
+			graph.AddEdge("S", "A", 1, nil)
+			graph.AddEdge("A", "T", 9, nil)
+			graph.AddEdge("S", "B", 2, nil)
+			graph.AddEdge("B", "T", 7.6, nil)
+			graph.AddEdge("S", "C", 3, nil)
+			graph.AddEdge("C", "T", 6.2, nil)
+
+			dist, prev, err := graph.DijkstraAll("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist["T"]).Should(BeNumerically("~", 9.2, 1e-9))
+			Expect(prev["T"]).Should(ConsistOf(ID("B"), ID("C")))
+			_, count, _ := graph.CountShortestPaths("S", "T")
+			Expect(count).Should(Equal(2))
+		})
+
+		It("Given an unreachable vertex or the source itself, when call all shortest paths api, then get no path or the vertex itself.", func() {
+			dist, paths, err := graph.AllShortestPaths("S", "X")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(paths).Should(BeNil())
+			_, count, _ := graph.CountShortestPaths("S", "X")
+			Expect(count).Should(BeZero())
+
+			dist, paths, err = graph.AllShortestPaths("S", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(0))
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"S"}}))
+			_, count, _ = graph.CountShortestPaths("S", "S")
+			Expect(count).Should(Equal(1))
+		})
+
+		It("Given distances differ by rounding errors, when call all shortest paths api, then they are equal within the tolerance of the graph.", func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("B", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "B", 0.1, nil)
+			graph.AddEdge("B", "A", 0.2, nil)
+			graph.AddEdge("S", "A", 0.3, nil)
+			graph.AddEdge("A", "T", 1, nil)
+
+			_, count, err := graph.CountShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(count).Should(Equal(2))
+
+			graph.SetTolerance(0)
+			_, count, err = graph.CountShortestPaths("S", "T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(count).Should(Equal(1))
+		})
+	})
+})
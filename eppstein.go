@@ -0,0 +1,248 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// sidetrack is an edge not in the shortest path tree to the destination,
+// delta is the extra distance of taking the edge instead of following the tree.
+type sidetrack struct {
+	from  ID
+	to    ID
+	delta float64
+	index int
+}
+
+// sidetrackHeap is a persistent leftist heap of the best sidetracks of the vertices along a tree path.
+type sidetrackHeap struct {
+	sidetrack   *sidetrack
+	rank        int
+	left, right *sidetrackHeap
+}
+
+func (h *sidetrackHeap) getRank() int {
+	if h == nil {
+		return 0
+	}
+
+	return h.rank
+}
+
+// mergeSidetrackHeap merges two heaps by copying the nodes along the right spine, the input heaps are not changed.
+func mergeSidetrackHeap(a, b *sidetrackHeap) *sidetrackHeap {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if b.sidetrack.delta < a.sidetrack.delta {
+		a, b = b, a
+	}
+
+	merged := &sidetrackHeap{a.sidetrack, 0, a.left, mergeSidetrackHeap(a.right, b)}
+	if merged.left.getRank() < merged.right.getRank() {
+		merged.left, merged.right = merged.right, merged.left
+	}
+	merged.rank = merged.right.getRank() + 1
+
+	return merged
+}
+
+// walkCandidate is a node of the path graph, which represents a walk by its sidetracks.
+// The last sidetrack comes either from a tree heap node, or from the sorted sidetracks of its tail vertex.
+type walkCandidate struct {
+	dist     float64
+	node     *sidetrackHeap
+	last     *sidetrack
+	prefix   *walkCandidate
+	sequence int
+}
+
+type walkQueue []*walkCandidate
+
+func (queue walkQueue) Len() int {
+	return len(queue)
+}
+
+func (queue walkQueue) Less(i, j int) bool {
+	if queue[i].dist != queue[j].dist {
+		return queue[i].dist < queue[j].dist
+	}
+
+	return queue[i].sequence < queue[j].sequence
+}
+
+func (queue walkQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *walkQueue) Push(x interface{}) {
+	*queue = append(*queue, x.(*walkCandidate))
+}
+
+func (queue *walkQueue) Pop() interface{} {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return item
+}
+
+// Eppstein gets top k shortest path between two vertex in the graph, the paths may contain loops.
+// The weight of a missing path is +Inf and the path is nil.
+// https://www.ics.uci.edu/~eppstein/pubs/Epp-SJC-98.pdf
+func (graph *Graph) Eppstein(source, destination ID, topK int) ([]float64, [][]ID, error) {
+	if _, exists := graph.vertices[source]; !exists {
+		return nil, nil, &VertexError{ErrVertexNotFound, source}
+	}
+
+	dist, next, order, err := graph.reverseDijkstra(destination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	distTopK := make([]float64, topK)
+	pathTopK := make([][]ID, topK)
+	for i := 0; i < topK; i++ {
+		distTopK[i] = math.Inf(1)
+	}
+	if topK == 0 || dist[source] == math.Inf(1) {
+		return distTopK, pathTopK, nil
+	}
+
+	sidetracks := make(map[ID][]*sidetrack)
+	trees := make(map[ID]*sidetrackHeap)
+	for _, from := range order {
+		for _, to := range graph.egressIDs(from) {
+			edge := graph.egress[from][to]
+			if !edge.enable || dist[to] == math.Inf(1) || next[from] == to {
+				continue
+			}
+			sidetracks[from] = append(sidetracks[from], &sidetrack{from, to, edge.getWeight() + dist[to] - dist[from], 0})
+		}
+		sort.SliceStable(sidetracks[from], func(i, j int) bool {
+			return sidetracks[from][i].delta < sidetracks[from][j].delta
+		})
+		for i, each := range sidetracks[from] {
+			each.index = i
+		}
+
+		trees[from] = trees[next[from]]
+		if len(sidetracks[from]) != 0 {
+			trees[from] = mergeSidetrackHeap(trees[from], &sidetrackHeap{sidetracks[from][0], 1, nil, nil})
+		}
+	}
+
+	distTopK[0] = dist[source]
+	pathTopK[0] = graph.getWalk(source, destination, next, nil)
+
+	var sequence int
+	queue := &walkQueue{}
+	push := func(candidate *walkCandidate) {
+		sequence++
+		candidate.sequence = sequence
+		heap.Push(queue, candidate)
+	}
+	if root := trees[source]; root != nil {
+		push(&walkCandidate{dist[source] + root.sidetrack.delta, root, root.sidetrack, nil, 0})
+	}
+
+	for k := 1; k < topK && queue.Len() != 0; k++ {
+		candidate := heap.Pop(queue).(*walkCandidate)
+		distTopK[k] = candidate.dist
+		pathTopK[k] = graph.getWalk(source, destination, next, candidate)
+
+		base := candidate.dist - candidate.last.delta
+		if candidate.node != nil {
+			for _, child := range []*sidetrackHeap{candidate.node.left, candidate.node.right} {
+				if child != nil {
+					push(&walkCandidate{base + child.sidetrack.delta, child, child.sidetrack, candidate.prefix, 0})
+				}
+			}
+		}
+		if siblings := sidetracks[candidate.last.from]; candidate.last.index+1 < len(siblings) {
+			sibling := siblings[candidate.last.index+1]
+			push(&walkCandidate{base + sibling.delta, nil, sibling, candidate.prefix, 0})
+		}
+		if root := trees[candidate.last.to]; root != nil {
+			push(&walkCandidate{candidate.dist + root.sidetrack.delta, root, root.sidetrack, candidate, 0})
+		}
+	}
+
+	return distTopK, pathTopK, nil
+}
+
+// reverseDijkstra gets the shortest distance from all vertices to the destination over the ingress edges,
+// together with the next vertex of each vertex in the shortest path tree and the vertices reaching the destination in the order of their distances.
+func (graph *Graph) reverseDijkstra(destination ID) (dist map[ID]float64, next map[ID]ID, order []ID, err error) {
+	if _, exists := graph.vertices[destination]; !exists {
+		return nil, nil, nil, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	dist = make(map[ID]float64)
+	next = make(map[ID]ID)
+	queue := newWeightQueue(Shortest(nil))
+
+	for _, id := range graph.vertexIDs() {
+		next[id] = nil
+		if id != destination {
+			dist[id] = math.Inf(1)
+		} else {
+			dist[id] = 0
+		}
+		queue.insert(id, dist[id])
+	}
+
+	for queue.num() != 0 {
+		min := queue.extractMin()
+		if dist[min] == math.Inf(1) {
+			continue
+		}
+		order = append(order, min)
+		for _, from := range graph.ingressIDs(min) {
+			edge := graph.ingress[min][from]
+			if edge.getWeight() < 0 {
+				return nil, nil, nil, &EdgeError{ErrNegativeWeight, from, min}
+			}
+			if _, exists := dist[from]; !exists || !edge.enable {
+				continue
+			}
+			if weight := dist[min] + edge.getWeight(); weight < dist[from] {
+				queue.decreaseKey(from, weight)
+				next[from] = min
+				dist[from] = weight
+			}
+		}
+	}
+
+	return
+}
+
+// getWalk gets the walk from the source to the destination which follows the shortest path tree except the sidetracks of the candidate.
+func (graph *Graph) getWalk(source, destination ID, next map[ID]ID, candidate *walkCandidate) []ID {
+	var tracks []*sidetrack
+	for ; candidate != nil; candidate = candidate.prefix {
+		tracks = append(tracks, candidate.last)
+	}
+
+	walk := []ID{source}
+	vertex := source
+	for i := len(tracks) - 1; i >= 0; i-- {
+		for vertex != tracks[i].from {
+			vertex = next[vertex]
+			walk = append(walk, vertex)
+		}
+		vertex = tracks[i].to
+		walk = append(walk, vertex)
+	}
+	for vertex != destination {
+		vertex = next[vertex]
+		walk = append(walk, vertex)
+	}
+
+	return walk
+}
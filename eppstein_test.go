@@ -0,0 +1,151 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var _ = Describe("Tests of Eppstein", func() {
+	var (
+		graph *Graph
+	)
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "T", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call eppstein api with non-existed vertex, then get ErrVertexNotFound.", func() {
+			dist, path, err := graph.Eppstein("X", "T", 3)
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(dist).Should(BeNil())
+			Expect(path).Should(BeNil())
+
+			_, _, err = graph.Eppstein("S", "X", 3)
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		})
+
+		It("Given a graph with negative edge, when call eppstein api, then get ErrNegativeWeight.", func() {
+			graph.UpdateEdgeWeight("S", "T", -1)
+
+			_, _, err := graph.Eppstein("S", "T", 3)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+		})
+
+		It("Given an unreachable destination, when call eppstein api, then get +Inf and nil paths.", func() {
+			dist, path, err := graph.Eppstein("T", "S", 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo([]float64{math.Inf(1), math.Inf(1)}))
+			Expect(path).Should(BeEquivalentTo([][]ID{nil, nil}))
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertexWithEdges(&myVertex{"C", map[ID]float64{"D": 3, "E": 2}, map[ID]float64{}})
+			graph.AddVertexWithEdges(&myVertex{"D", map[ID]float64{"F": 4}, map[ID]float64{"C": 3, "E": 1}})
+			graph.AddVertexWithEdges(&myVertex{"E", map[ID]float64{"D": 1, "F": 2, "G": 3}, map[ID]float64{"C": 2}})
+			graph.AddVertexWithEdges(&myVertex{"F", map[ID]float64{"G": 2, "H": 1}, map[ID]float64{"D": 4, "E": 2}})
+			graph.AddVertexWithEdges(&myVertex{"G", map[ID]float64{"H": 2}, map[ID]float64{"E": 3, "F": 2}})
+			graph.AddVertexWithEdges(&myVertex{"H", map[ID]float64{}, map[ID]float64{"F": 1, "G": 2}})
+			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph without loops, when call eppstein api, then get the same paths as yen.", func() {
+			dist, path, err := graph.Eppstein("C", "H", 9)
+			Expect(err).ShouldNot(HaveOccurred())
+			expectedDist, expectedPath, _ := graph.Yen("C", "H", 9)
+			Expect(dist).Should(BeEquivalentTo(expectedDist))
+			Expect(path).Should(ConsistOf(expectedPath))
+		})
+
+		It("Given a graph with loops, when call eppstein api, then get the shortest paths going around the loops.", func() {
+			graph.AddEdge("F", "E", 1, nil)
+			dist, path, err := graph.Eppstein("C", "H", 6)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo([]float64{5, 7, 8, 8, 8, 8}))
+			Expect(path[0]).Should(BeEquivalentTo([]ID{"C", "E", "F", "H"}))
+			Expect(path[1]).Should(BeEquivalentTo([]ID{"C", "E", "G", "H"}))
+			Expect(path[2:]).Should(ConsistOf([]ID{"C", "D", "F", "H"}, []ID{"C", "E", "F", "G", "H"}, []ID{"C", "E", "D", "F", "H"}, []ID{"C", "E", "F", "E", "F", "H"}))
+		})
+
+		It("Given the source is the destination, when call eppstein api, then get the vertex itself first and then the loops through it.", func() {
+			graph.AddEdge("H", "C", 1, nil)
+			dist, path, err := graph.Eppstein("H", "H", 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo([]float64{0, 6}))
+			Expect(path).Should(BeEquivalentTo([][]ID{{"H"}, {"H", "C", "E", "F", "H"}}))
+		})
+	})
+
+	Context("exhaustive test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(3))
+			graph = NewGraph()
+			for i := 0; i < 6; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 6; i++ {
+				for j := 0; j < 6; j++ {
+					if i != j && random.Intn(2) == 0 {
+						graph.AddEdge(i, j, float64(1+random.Intn(5)), nil)
+					}
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with positive edges, when call eppstein api, then get the weights of all the walks in order.", func() {
+			const bound = 20
+			var expectedDist []float64
+			var walk func(vertex ID, weight float64)
+			walk = func(vertex ID, weight float64) {
+				if weight > bound {
+					return
+				}
+				if vertex == 5 {
+					expectedDist = append(expectedDist, weight)
+				}
+				for to, edge := range graph.egress[vertex] {
+					walk(to, weight+edge.weight)
+				}
+			}
+			walk(0, 0)
+			sort.Float64s(expectedDist)
+			Expect(len(expectedDist)).Should(BeNumerically(">", 10))
+
+			dist, path, err := graph.Eppstein(0, 5, len(expectedDist))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(expectedDist))
+
+			seen := make(map[string]bool)
+			for i := range path {
+				Expect(graph.GetPathWeight(path[i])).Should(BeEquivalentTo(dist[i]))
+				Expect(seen[fmt.Sprint(path[i])]).Should(BeFalse())
+				seen[fmt.Sprint(path[i])] = true
+			}
+		})
+	})
+})
@@ -0,0 +1,74 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the graph operations and algorithms.
+// They are wrapped in VertexError, EdgeError or CycleError together with the offending ids if any, use errors.Is to check them.
+var (
+	ErrVertexNotFound     = errors.New("vertex is not found")
+	ErrEdgeNotFound       = errors.New("edge is not found")
+	ErrDuplicateVertex    = errors.New("vertex is duplicate")
+	ErrDuplicateEdge      = errors.New("edge is duplicate")
+	ErrNegativeWeight     = errors.New("negative weight is not allowed")
+	ErrReservedWeight     = errors.New("-inf weight is reserved for internal usage")
+	ErrWeightOutOfRange   = errors.New("weight is out of range")
+	ErrUnrelatedEdge      = errors.New("edge is unrelated to the vertex")
+	ErrEmptyPath          = errors.New("path is empty")
+	ErrCycle              = errors.New("graph is not acyclic")
+	ErrJournalDisabled    = errors.New("journal is not enabled")
+	ErrCheckpointNotFound = errors.New("checkpoint is not found")
+	ErrCorruptData        = errors.New("encoded data is corrupt")
+	ErrStale              = errors.New("graph is changed after the preprocessing")
+	ErrIDType             = errors.New("id type matches no vertex")
+)
+
+// VertexError records an error and the vertex caused it.
+type VertexError struct {
+	Err error
+	ID  ID
+}
+
+func (e *VertexError) Error() string {
+	return fmt.Sprintf("Vertex %v: %v", e.ID, e.Err)
+}
+
+// Unwrap gets the underlying error.
+func (e *VertexError) Unwrap() error {
+	return e.Err
+}
+
+// EdgeError records an error and the edge caused it.
+type EdgeError struct {
+	Err  error
+	From ID
+	To   ID
+}
+
+func (e *EdgeError) Error() string {
+	return fmt.Sprintf("Edge from %v to %v: %v", e.From, e.To, e.Err)
+}
+
+// Unwrap gets the underlying error.
+func (e *EdgeError) Unwrap() error {
+	return e.Err
+}
+
+// CycleError records an error and the cycle caused it, the first vertex of the cycle is repeated at its end.
+type CycleError struct {
+	Err   error
+	Cycle []ID
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("Cycle %v: %v", e.Cycle, e.Err)
+}
+
+// Unwrap gets the underlying error.
+func (e *CycleError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,95 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of errors", func() {
+	var (
+		graph *Graph
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.AddVertex("S", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "T", 1, nil)
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	It("Given a graph, when operate on vertices not in the graph, then get VertexError with ErrVertexNotFound", func() {
+		var vertexErr *VertexError
+
+		_, err := graph.GetVertex("X")
+		Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		_, err = graph.GetEdge("X", "T")
+		Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		err = graph.AddEdge("S", "X", 1, nil)
+		Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		Expect(errors.As(err, &vertexErr)).Should(BeTrue())
+		Expect(vertexErr.ID).Should(BeEquivalentTo("X"))
+
+		_, _, err = graph.Dijkstra("X")
+		Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		_, _, err = graph.Yen("X", "S", 2)
+		Expect(errors.As(err, &vertexErr)).Should(BeTrue())
+		Expect(vertexErr.ID).Should(BeEquivalentTo("X"))
+		_, _, err = graph.Kisp("S", "X", 2)
+		Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+	})
+
+	It("Given a graph, when operate on edges not in the graph, then get EdgeError with ErrEdgeNotFound", func() {
+		var edgeErr *EdgeError
+
+		_, err := graph.GetEdge("T", "S")
+		Expect(errors.Is(err, ErrEdgeNotFound)).Should(BeTrue())
+		err = graph.UpdateEdgeWeight("T", "S", 1)
+		Expect(errors.As(err, &edgeErr)).Should(BeTrue())
+		Expect(edgeErr.From).Should(BeEquivalentTo("T"))
+		Expect(edgeErr.To).Should(BeEquivalentTo("S"))
+	})
+
+	It("Given a graph, when add duplicate vertices or edges, then get ErrDuplicateVertex or ErrDuplicateEdge", func() {
+		err := graph.AddVertex("S", nil)
+		Expect(errors.Is(err, ErrDuplicateVertex)).Should(BeTrue())
+		err = graph.AddVertexWithEdges(&myVertex{"T", map[ID]float64{}, map[ID]float64{}})
+		Expect(errors.Is(err, ErrDuplicateVertex)).Should(BeTrue())
+		err = graph.AddEdge("S", "T", 1, nil)
+		Expect(errors.Is(err, ErrDuplicateEdge)).Should(BeTrue())
+		Expect(err.Error()).Should(ContainSubstring("Edge from S to T"))
+	})
+
+	It("Given a graph, when add or update edges with -inf or negative weight, then get ErrReservedWeight or ErrNegativeWeight", func() {
+		var edgeErr *EdgeError
+
+		err := graph.AddEdge("T", "S", math.Inf(-1), nil)
+		Expect(errors.Is(err, ErrReservedWeight)).Should(BeTrue())
+		err = graph.UpdateEdgeWeight("S", "T", math.Inf(-1))
+		Expect(errors.Is(err, ErrReservedWeight)).Should(BeTrue())
+		err = graph.AddVertexWithEdges(&myVertex{"X", map[ID]float64{"S": math.Inf(-1)}, map[ID]float64{}})
+		Expect(errors.Is(err, ErrReservedWeight)).Should(BeTrue())
+
+		graph.UpdateEdgeWeight("S", "T", -1)
+		_, _, err = graph.Dijkstra("S")
+		Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+		_, _, err = graph.Yen("S", "T", 2)
+		Expect(errors.As(err, &edgeErr)).Should(BeTrue())
+		Expect(edgeErr.From).Should(BeEquivalentTo("S"))
+		Expect(edgeErr.To).Should(BeEquivalentTo("T"))
+		_, _, err = graph.Kisp("S", "T", 2)
+		Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+	})
+
+	It("Given an error, when get its message, then the offending ids are included", func() {
+		Expect((&VertexError{ErrVertexNotFound, "X"}).Error()).Should(Equal("Vertex X: vertex is not found"))
+		Expect((&EdgeError{ErrEdgeNotFound, "X", "Y"}).Error()).Should(Equal("Edge from X to Y: edge is not found"))
+	})
+})
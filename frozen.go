@@ -0,0 +1,205 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+)
+
+// FrozenGraph is an immutable snapshot of a graph.
+// It supports all the methods of the graph which do not change it, which can run concurrently without locks,
+// while the graph it is frozen from keeps accepting changes.
+// ShortestPathTree is left out as it follows the changes of the graph, Clone gets a mutable copy for it and for Diff.
+type FrozenGraph struct {
+	graph *Graph
+}
+
+// Freeze gets an immutable snapshot of the graph, including its disabled edges and settings.
+// Later changes of the graph are not seen by the snapshot, and the compact form of the snapshot is built only once.
+func (graph *Graph) Freeze() *FrozenGraph {
+	frozen := graph.Clone(false)
+	frozen.snapshot = frozen.buildCompact()
+
+	return &FrozenGraph{frozen}
+}
+
+// Version gets the version of the graph when it is frozen.
+func (frozen *FrozenGraph) Version() uint64 {
+	return frozen.graph.version
+}
+
+// Clone gets a mutable copy of the snapshot as the Clone of the graph does.
+func (frozen *FrozenGraph) Clone(deep bool) *Graph {
+	return frozen.graph.Clone(deep)
+}
+
+// Hash gets the hash of the vertices and edges with their weights in the snapshot.
+func (frozen *FrozenGraph) Hash() uint64 {
+	return frozen.graph.Hash()
+}
+
+// Equal reports whether the snapshot and the other graph have the same vertices and the same edges with the equal weights.
+func (frozen *FrozenGraph) Equal(other *Graph, opts EqualOptions) bool {
+	return frozen.graph.Equal(other, opts)
+}
+
+// CheckIntegrity checks if any edge connects to or from unknown vertex in the snapshot.
+func (frozen *FrozenGraph) CheckIntegrity() error {
+	return frozen.graph.CheckIntegrity()
+}
+
+// GetVertex gets the vertex by the input id.
+func (frozen *FrozenGraph) GetVertex(id ID) (vertex interface{}, err error) {
+	return frozen.graph.GetVertex(id)
+}
+
+// GetEdge gets the edge between the two vertices by the input ids.
+func (frozen *FrozenGraph) GetEdge(from ID, to ID) (interface{}, error) {
+	return frozen.graph.GetEdge(from, to)
+}
+
+// GetEdgeWeight gets the weight of the edge between the two vertices by the input ids.
+func (frozen *FrozenGraph) GetEdgeWeight(from ID, to ID) (float64, error) {
+	return frozen.graph.GetEdgeWeight(from, to)
+}
+
+// GetPathWeight gets the total weight along the path by input ids.
+func (frozen *FrozenGraph) GetPathWeight(path []ID) (totalWeight float64) {
+	return frozen.graph.GetPathWeight(path)
+}
+
+// GetPathWeightWith gets the weight along the path by input ids under the algebra.
+func (frozen *FrozenGraph) GetPathWeightWith(path []ID, algebra PathAlgebra) (PathWeight, error) {
+	return frozen.graph.GetPathWeightWith(path, algebra)
+}
+
+// Dijkstra gets the shortest path from one vertex to all other vertices in the snapshot.
+func (frozen *FrozenGraph) Dijkstra(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.Dijkstra(source)
+}
+
+// DijkstraCtx gets the shortest path from one vertex to all other vertices in the snapshot, and stops when the context is done.
+func (frozen *FrozenGraph) DijkstraCtx(ctx context.Context, source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.DijkstraCtx(ctx, source)
+}
+
+// DijkstraQueue gets the shortest path from one vertex to all other vertices in the snapshot with the priority queue created by the factory.
+func (frozen *FrozenGraph) DijkstraQueue(source ID, queue QueueFactory) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.DijkstraQueue(source, queue)
+}
+
+// ZeroOneBFS gets the shortest path from one vertex to all other vertices in the snapshot whose edge weights are either 0 or 1.
+func (frozen *FrozenGraph) ZeroOneBFS(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.ZeroOneBFS(source)
+}
+
+// Dial gets the shortest path from one vertex to all other vertices in the snapshot whose edge weights are integers no more than the max weight.
+func (frozen *FrozenGraph) Dial(source ID, maxWeight int) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.Dial(source, maxWeight)
+}
+
+// DijkstraWith gets the best path under the algebra from one vertex to all other vertices in the snapshot.
+func (frozen *FrozenGraph) DijkstraWith(source ID, algebra PathAlgebra) (dist map[ID]PathWeight, prev map[ID]ID, err error) {
+	return frozen.graph.DijkstraWith(source, algebra)
+}
+
+// DijkstraAll gets the shortest distance and all the predecessors on the equal-cost shortest paths from one vertex to all other vertices in the snapshot.
+func (frozen *FrozenGraph) DijkstraAll(source ID) (dist map[ID]float64, prev map[ID][]ID, err error) {
+	return frozen.graph.DijkstraAll(source)
+}
+
+// AllShortestPaths gets all the equal-cost shortest paths between two vertex in the snapshot.
+func (frozen *FrozenGraph) AllShortestPaths(source, destination ID) (dist float64, paths [][]ID, err error) {
+	return frozen.graph.AllShortestPaths(source, destination)
+}
+
+// CountShortestPaths gets the number of the equal-cost shortest paths between two vertex in the snapshot.
+func (frozen *FrozenGraph) CountShortestPaths(source, destination ID) (dist float64, count int, err error) {
+	return frozen.graph.CountShortestPaths(source, destination)
+}
+
+// Yen gets top k shortest loopless path between two vertex in the snapshot.
+func (frozen *FrozenGraph) Yen(source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return frozen.graph.Yen(source, destination, topK)
+}
+
+// YenCtx gets top k shortest loopless path between two vertex in the snapshot, and stops when the context is done.
+func (frozen *FrozenGraph) YenCtx(ctx context.Context, source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return frozen.graph.YenCtx(ctx, source, destination, topK)
+}
+
+// YenWith gets top k best loopless path under the algebra between two vertex in the snapshot.
+func (frozen *FrozenGraph) YenWith(source, destination ID, topK int, algebra PathAlgebra) ([]PathWeight, [][]ID, error) {
+	return frozen.graph.YenWith(source, destination, topK, algebra)
+}
+
+// YenIter gets an iterator of the shortest loopless paths between two vertex in the snapshot.
+func (frozen *FrozenGraph) YenIter(source, destination ID) *YenIterator {
+	return frozen.graph.YenIter(source, destination)
+}
+
+// Kisp gets top k shortest independent path between two vertex in the snapshot.
+func (frozen *FrozenGraph) Kisp(source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return frozen.graph.Kisp(source, destination, topK)
+}
+
+// KispCtx gets top k shortest independent path between two vertex in the snapshot, and stops when the context is done.
+func (frozen *FrozenGraph) KispCtx(ctx context.Context, source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return frozen.graph.KispCtx(ctx, source, destination, topK)
+}
+
+// Eppstein gets top k shortest path, which may contain loops, between two vertex in the snapshot.
+func (frozen *FrozenGraph) Eppstein(source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return frozen.graph.Eppstein(source, destination, topK)
+}
+
+// Pareto gets all the non-dominated paths between two vertex in the snapshot under multiple criteria.
+func (frozen *FrozenGraph) Pareto(source, destination ID, criteria []EdgeMetric, maxLabels int) (costs [][]float64, paths [][]ID, err error) {
+	return frozen.graph.Pareto(source, destination, criteria, maxLabels)
+}
+
+// WidestPath gets the widest path from one vertex to all other vertices in the snapshot.
+func (frozen *FrozenGraph) WidestPath(source ID) (width map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.WidestPath(source)
+}
+
+// ShortestWidestPath gets the shortest path among all the widest paths between two vertices in the snapshot.
+func (frozen *FrozenGraph) ShortestWidestPath(source, destination ID, capacity EdgeMetric) (width float64, dist float64, path []ID, err error) {
+	return frozen.graph.ShortestWidestPath(source, destination, capacity)
+}
+
+// WidestShortestPath gets the widest path among all the shortest paths between two vertices in the snapshot.
+func (frozen *FrozenGraph) WidestShortestPath(source, destination ID, capacity EdgeMetric) (width float64, dist float64, path []ID, err error) {
+	return frozen.graph.WidestShortestPath(source, destination, capacity)
+}
+
+// DAGShortestPaths gets the shortest path from one vertex to all other vertices in the snapshot if it is acyclic.
+func (frozen *FrozenGraph) DAGShortestPaths(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.DAGShortestPaths(source)
+}
+
+// DAGLongestPaths gets the longest path from one vertex to all other vertices in the snapshot if it is acyclic.
+func (frozen *FrozenGraph) DAGLongestPaths(source ID) (dist map[ID]float64, prev map[ID]ID, err error) {
+	return frozen.graph.DAGLongestPaths(source)
+}
+
+// CriticalPath gets the schedule of the snapshot by the critical path method if it is acyclic.
+func (frozen *FrozenGraph) CriticalPath() (*Schedule, error) {
+	return frozen.graph.CriticalPath()
+}
+
+// BuildLandmarks selects n landmarks of the snapshot by the strategy and calculates their distances for the A* queries.
+func (frozen *FrozenGraph) BuildLandmarks(n int, strategy LandmarkStrategy) (*Landmarks, error) {
+	return frozen.graph.BuildLandmarks(n, strategy)
+}
+
+// BuildHubLabels builds the hub labels of the snapshot by pruned landmark labeling.
+func (frozen *FrozenGraph) BuildHubLabels(withPaths bool) (*HubLabels, error) {
+	return frozen.graph.BuildHubLabels(withPaths)
+}
+
+// BuildContractionHierarchy builds the contraction hierarchy of a mutable copy of the snapshot,
+// so that the snapshot is not changed by the UpdateEdgeWeight of the hierarchy.
+func (frozen *FrozenGraph) BuildContractionHierarchy() (*ContractionHierarchy, error) {
+	return frozen.graph.Clone(false).BuildContractionHierarchy()
+}
@@ -0,0 +1,148 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"reflect"
+	"sync"
+)
+
+var _ = Describe("Tests of FrozenGraph", func() {
+	var (
+		graph *Graph
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.AddVertexWithEdges(&myVertex{"C", map[ID]float64{"D": 3, "E": 2}, map[ID]float64{}})
+		graph.AddVertexWithEdges(&myVertex{"D", map[ID]float64{"F": 4}, map[ID]float64{"C": 3, "E": 1}})
+		graph.AddVertexWithEdges(&myVertex{"E", map[ID]float64{"D": 1, "F": 2, "G": 3}, map[ID]float64{"C": 2}})
+		graph.AddVertexWithEdges(&myVertex{"F", map[ID]float64{"G": 2, "H": 1}, map[ID]float64{"D": 4, "E": 2}})
+		graph.AddVertexWithEdges(&myVertex{"G", map[ID]float64{"H": 2}, map[ID]float64{"E": 3, "F": 2}})
+		graph.AddVertexWithEdges(&myVertex{"H", map[ID]float64{}, map[ID]float64{"F": 1, "G": 2}})
+		Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	It("Given a frozen graph, when change the original graph, then the frozen graph is not changed.", func() {
+		graph.DisableEdge("C", "D")
+		frozen := graph.Freeze()
+		expectedDist, expectedPath, _ := frozen.Yen("C", "H", 5)
+
+		graph.UpdateEdgeWeight("F", "H", 10)
+		graph.AddVertex("X", nil)
+		graph.AddEdge("C", "X", 1, nil)
+		graph.AddEdge("X", "H", 1, nil)
+		graph.DeleteVertex("G")
+		graph.Reset()
+
+		dist, path, err := frozen.Yen("C", "H", 5)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist).Should(Equal(expectedDist))
+		Expect(path).Should(Equal(expectedPath))
+		Expect(frozen.GetEdgeWeight("F", "H")).Should(BeEquivalentTo(1))
+		_, err = frozen.GetVertex("X")
+		Expect(err).Should(HaveOccurred())
+
+		dist, path, err = graph.Yen("C", "H", 1)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist[0]).Should(BeEquivalentTo(2))
+		Expect(path[0]).Should(Equal([]ID{"C", "X", "H"}))
+	})
+
+	It("Given the methods of the graph, when list the ones of frozen graph, then all the methods not changing the graph are supported with the same signatures.", func() {
+		changing := map[string]bool{
+			"AddEdge": true, "AddVertex": true, "AddVertexWithEdges": true, "Apply": true, "Batch": true,
+			"DeleteEdge": true, "DeleteVertex": true, "UpdateEdgeWeight": true, "DisableEdge": true, "DisablePath": true, "DisableVertex": true, "Reset": true,
+			"EnableJournal": true, "DisableJournal": true, "Undo": true, "Redo": true, "Checkpoint": true, "RestoreCheckpoint": true,
+			"SetDeterministic": true, "SetOrder": true, "SetTolerance": true, "SetWorkers": true, "Freeze": true,
+			"OnVertexAdded": true, "OnVertexDeleted": true, "OnEdgeAdded": true, "OnEdgeWeightUpdated": true, "OnEdgeDeleted": true,
+			"ShortestPathTree": true,
+		}
+		graphType, frozenType := reflect.TypeOf(graph), reflect.TypeOf(&FrozenGraph{})
+		for i := 0; i < graphType.NumMethod(); i++ {
+			method := graphType.Method(i)
+			if changing[method.Name] {
+				continue
+			}
+			frozenMethod, exists := frozenType.MethodByName(method.Name)
+			Expect(exists).Should(BeTrue(), method.Name)
+			Expect(frozenMethod.Type.NumIn()).Should(Equal(method.Type.NumIn()), method.Name)
+			for j := 1; j < method.Type.NumIn(); j++ {
+				Expect(frozenMethod.Type.In(j)).Should(Equal(method.Type.In(j)), method.Name)
+			}
+			Expect(frozenMethod.Type.NumOut()).Should(Equal(method.Type.NumOut()), method.Name)
+			for j := 0; j < method.Type.NumOut(); j++ {
+				Expect(frozenMethod.Type.Out(j)).Should(Equal(method.Type.Out(j)), method.Name)
+			}
+		}
+	})
+
+	It("Given a frozen graph, when build and update its contraction hierarchy, then the snapshot is not changed.", func() {
+		frozen := graph.Freeze()
+		ch, err := frozen.BuildContractionHierarchy()
+		Expect(err).ShouldNot(HaveOccurred())
+		dist, path, err := ch.ShortestPath("C", "H")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist).Should(BeEquivalentTo(5))
+		Expect(path).Should(Equal([]ID{"C", "E", "F", "H"}))
+
+		Expect(ch.UpdateEdgeWeight("E", "F", 10)).Should(Succeed())
+		dist, _, _ = ch.ShortestPath("C", "H")
+		Expect(dist).Should(BeEquivalentTo(7))
+		Expect(frozen.GetEdgeWeight("E", "F")).Should(BeEquivalentTo(2))
+		Expect(frozen.graph.listeners).Should(BeEmpty())
+		Expect(frozen.Equal(graph, EqualOptions{})).Should(BeTrue())
+		Expect(frozen.CheckIntegrity()).Should(Succeed())
+	})
+
+	It("Given a frozen graph, when query it, then its compact form is built only once.", func() {
+		frozen := graph.Freeze()
+		compact := frozen.graph.compact()
+		Expect(frozen.graph.compact()).Should(BeIdenticalTo(compact))
+		graph.AddVertex("Y", nil)
+		Expect(frozen.graph.compact()).Should(BeIdenticalTo(compact))
+		Expect(graph.compact()).ShouldNot(BeIdenticalTo(compact))
+	})
+
+	It("Given a frozen graph, when query it concurrently while the original graph changes, then get the same results as the sequential queries.", func() {
+		frozen := graph.Freeze()
+		expectedDist, expectedPrev, _ := frozen.Dijkstra("C")
+		expectedYenDist, expectedYenPath, _ := frozen.Yen("C", "H", 7)
+		expectedKispDist, expectedKispPath, _ := frozen.Kisp("C", "H", 3)
+		_, expectedPaths, _ := frozen.AllShortestPaths("C", "H")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					dist, prev, _ := frozen.Dijkstra("C")
+					Expect(dist).Should(Equal(expectedDist))
+					Expect(prev).Should(Equal(expectedPrev))
+					yenDist, yenPath, _ := frozen.Yen("C", "H", 7)
+					Expect(yenDist).Should(Equal(expectedYenDist))
+					Expect(yenPath).Should(Equal(expectedYenPath))
+					kispDist, kispPath, _ := frozen.Kisp("C", "H", 3)
+					Expect(kispDist).Should(Equal(expectedKispDist))
+					Expect(kispPath).Should(Equal(expectedKispPath))
+					_, paths, _ := frozen.AllShortestPaths("C", "H")
+					Expect(paths).Should(ConsistOf(expectedPaths))
+				}
+			}()
+		}
+		for j := 0; j < 20; j++ {
+			graph.UpdateEdgeWeight("C", "E", float64(j))
+			graph.DisableEdge("E", "F")
+			graph.Reset()
+		}
+		wg.Wait()
+	})
+})
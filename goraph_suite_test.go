@@ -3,6 +3,7 @@
 package goraph
 
 import (
+	"context"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"testing"
@@ -56,3 +57,16 @@ func (vertex *myVertex) Edges() (edges []Edge) {
 func (edge *myEdge) Get() (ID, ID, float64) {
 	return edge.from, edge.to, edge.weight
 }
+
+// countdownContext is done after its Err is called count times.
+type countdownContext struct {
+	context.Context
+	count int
+}
+
+func (ctx *countdownContext) Err() error {
+	if ctx.count--; ctx.count < 0 {
+		return context.Canceled
+	}
+	return nil
+}
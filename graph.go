@@ -3,8 +3,9 @@
 package goraph
 
 import (
-	"fmt"
 	"math"
+	"sort"
+	"sync"
 )
 
 // ID uniquely identify a vertex.
@@ -24,18 +25,47 @@ type Edge interface {
 	Get() (from ID, to ID, weight float64)
 }
 
+// DefaultTolerance is the default tolerance for two path weights to be considered equal.
+const DefaultTolerance = 1e-9
+
 // Graph is made up of vertices and edges.
 // Vertices in the graph must have an unique id.
 // Each edges in the graph connects two vertices directed with a weight.
 type Graph struct {
-	vertices map[ID]*vertex
-	egress   map[ID]map[ID]*edge
-	ingress  map[ID]map[ID]*edge
+	vertices      map[ID]*vertex
+	egress        map[ID]map[ID]*edge
+	ingress       map[ID]map[ID]*edge
+	tolerance     float64
+	sequence      int
+	deterministic bool
+	less          func(a, b ID) bool
+	workers       int
+	// snapshot is the compact form of a frozen graph, nil if the graph is mutable.
+	snapshot *compactGraph
+	// cache is the compact form of a mutable graph built at the cached version, nil after the graph is changed.
+	cache        *compactGraph
+	cacheVersion uint64
+	cacheLock    sync.Mutex
+	// listeners are notified after each change of the graph.
+	listeners []listener
+	// version increases after each change of the graph, and journal records the changes if it is enabled.
+	version uint64
+	journal *journal
+}
+
+// listener is notified after each change of the graph, with the weights before and after the change.
+type listener interface {
+	vertexAdded(id ID)
+	vertexDeleted(id ID)
+	edgeAdded(from, to ID, weight float64)
+	edgeUpdated(from, to ID, old, weight float64)
+	edgeDeleted(from, to ID, weight float64)
 }
 
 type vertex struct {
 	self   interface{}
 	enable bool
+	order  int
 }
 
 type edge struct {
@@ -55,10 +85,43 @@ func NewGraph() *Graph {
 	graph.vertices = make(map[ID]*vertex)
 	graph.egress = make(map[ID]map[ID]*edge)
 	graph.ingress = make(map[ID]map[ID]*edge)
+	graph.tolerance = DefaultTolerance
 
 	return graph
 }
 
+// SetDeterministic sets whether the algorithms visit vertices and edges in a stable order,
+// so that ties are always broken in the same way regardless of the map iteration order.
+// Among the predecessors with equal weights, the first one in order is chosen.
+// Among the paths with equal weights returned by Yen and AllShortestPaths, the lexicographically first one in order is returned first,
+// while the other k shortest paths algorithms return them in a reproducible order.
+// Vertices are ordered by their insertion order unless another order is set by SetOrder.
+// ShortestPathTree is not covered, the predecessors with equal distances it chooses while being repaired depend on the map iteration order.
+func (graph *Graph) SetDeterministic(deterministic bool) {
+	graph.deterministic = deterministic
+	graph.invalidate()
+}
+
+// SetOrder sets the order of vertices to break ties in the deterministic mode.
+// Vertices are ordered by their insertion order if the less function is nil.
+func (graph *Graph) SetOrder(less func(a, b ID) bool) {
+	graph.less = less
+	graph.invalidate()
+}
+
+// SetWorkers sets the number of workers to run the independent calculations of an algorithm in parallel, e.g. the spur paths of Yen.
+// The calculations are run sequentially if the number is not more than 1, which is the default.
+// The results are the same as the sequential ones. EdgeMetric used with parallel workers must be safe for concurrent use.
+func (graph *Graph) SetWorkers(workers int) {
+	graph.workers = workers
+}
+
+// SetTolerance sets the tolerance for two path weights to be considered equal.
+// Two weights are equal if their absolute difference is no more than the tolerance.
+func (graph *Graph) SetTolerance(tolerance float64) {
+	graph.tolerance = tolerance
+}
+
 // GetVertex get a vertex by input id.
 // Try to get a vertex not in the graph will get an error.
 func (graph *Graph) GetVertex(id ID) (vertex interface{}, err error) {
@@ -67,7 +130,7 @@ func (graph *Graph) GetVertex(id ID) (vertex interface{}, err error) {
 		return
 	}
 
-	err = fmt.Errorf("Vertex %v is not found", id)
+	err = &VertexError{ErrVertexNotFound, id}
 	return
 }
 
@@ -76,18 +139,18 @@ func (graph *Graph) GetVertex(id ID) (vertex interface{}, err error) {
 // Try to get the edge between two disconnected vertices will get an error.
 func (graph *Graph) GetEdge(from ID, to ID) (interface{}, error) {
 	if _, exists := graph.vertices[from]; !exists {
-		return nil, fmt.Errorf("Vertex(from) %v is not found", from)
+		return nil, &VertexError{ErrVertexNotFound, from}
 	}
 
 	if _, exists := graph.vertices[to]; !exists {
-		return nil, fmt.Errorf("Vertex(to) %v is not found", to)
+		return nil, &VertexError{ErrVertexNotFound, to}
 	}
 
 	if edge, exists := graph.egress[from][to]; exists {
 		return edge.self, nil
 	}
 
-	return nil, fmt.Errorf("Edge from %v to %v is not found", from, to)
+	return nil, &EdgeError{ErrEdgeNotFound, from, to}
 }
 
 // GetEdgeWeight gets the weight of the edge between the two vertices by input ids.
@@ -95,11 +158,11 @@ func (graph *Graph) GetEdge(from ID, to ID) (interface{}, error) {
 // Try to get the weight of the edge between two disconnected vertices will get +Inf.
 func (graph *Graph) GetEdgeWeight(from ID, to ID) (float64, error) {
 	if _, exists := graph.vertices[from]; !exists {
-		return math.Inf(1), fmt.Errorf("Vertex(from) %v is not found", from)
+		return math.Inf(1), &VertexError{ErrVertexNotFound, from}
 	}
 
 	if _, exists := graph.vertices[to]; !exists {
-		return math.Inf(1), fmt.Errorf("Vertex(to) %v is not found", to)
+		return math.Inf(1), &VertexError{ErrVertexNotFound, to}
 	}
 
 	if edge, exists := graph.egress[from][to]; exists {
@@ -112,15 +175,13 @@ func (graph *Graph) GetEdgeWeight(from ID, to ID) (float64, error) {
 // AddVertex adds a new vertex into the graph.
 // Try to add a duplicate vertex will get an error.
 func (graph *Graph) AddVertex(id ID, v interface{}) error {
-	if _, exists := graph.vertices[id]; exists {
-		return fmt.Errorf("Vertex %v is duplicate", id)
+	if graph.journal == nil {
+		return graph.changed(graph.addVertex(id, v))
 	}
 
-	graph.vertices[id] = &vertex{v, true}
-	graph.egress[id] = make(map[ID]*edge)
-	graph.ingress[id] = make(map[ID]*edge)
-
-	return nil
+	return graph.Batch(func(tx *Tx) error {
+		return tx.AddVertex(id, v)
+	})
 }
 
 // AddEdge adds a new edge between the vertices by the input ids.
@@ -128,132 +189,199 @@ func (graph *Graph) AddVertex(id ID, v interface{}) error {
 // Try to add an edge from or to a vertex not in the graph will get an error.
 // Try to add a duplicate edge will get an error.
 func (graph *Graph) AddEdge(from ID, to ID, weight float64, e interface{}) error {
+	if graph.journal == nil {
+		return graph.changed(graph.addEdge(from, to, weight, e))
+	}
+
+	return graph.Batch(func(tx *Tx) error {
+		return tx.AddEdge(from, to, weight, e)
+	})
+}
+
+// UpdateEdgeWeight updates the weight of the edge between vertices by the input ids.
+// Try to update an edge with -Inf weight will get an error.
+// Try to update an edge from or to a vertex not in the graph will get an error.
+// Try to update an edge between disconnected vertices will get an error.
+func (graph *Graph) UpdateEdgeWeight(from ID, to ID, weight float64) error {
+	if graph.journal == nil {
+		return graph.changed(graph.updateEdgeWeight(from, to, weight))
+	}
+
+	return graph.Batch(func(tx *Tx) error {
+		return tx.UpdateEdgeWeight(from, to, weight)
+	})
+}
+
+// DeleteVertex deletes a vertex from the graph and gets the value of the vertex.
+// Try to delete a vertex not in the graph will get an nil.
+func (graph *Graph) DeleteVertex(id ID) (deleted interface{}) {
+	if graph.journal == nil {
+		if vertex := graph.deleteVertex(id); vertex != nil {
+			graph.version++
+			return vertex.self
+		}
+		return nil
+	}
+
+	graph.Batch(func(tx *Tx) error {
+		deleted = tx.DeleteVertex(id)
+		return nil
+	})
+
+	return
+}
+
+// DeleteEdge deletes the edge between the vertices by the input id from the graph and gets the value of edge.
+// Try to delete an edge from or to a vertex not in the graph will get an error.
+// Try to delete an edge between disconnected vertices will get a nil.
+func (graph *Graph) DeleteEdge(from ID, to ID) (deleted interface{}) {
+	if graph.journal == nil {
+		if !graph.hasEdge(from, to) {
+			return nil
+		}
+		graph.version++
+		return graph.deleteEdge(from, to).self
+	}
+
+	graph.Batch(func(tx *Tx) error {
+		deleted = tx.DeleteEdge(from, to)
+		return nil
+	})
+
+	return
+}
+
+// changed increases the version if the single change made directly without the journal succeeds.
+// The change needs no transaction, since it either fails with nothing changed or succeeds.
+func (graph *Graph) changed(err error) error {
+	if err == nil {
+		graph.version++
+	}
+
+	return err
+}
+
+// addVertex adds the vertex without recording the change.
+func (graph *Graph) addVertex(id ID, v interface{}) error {
+	if _, exists := graph.vertices[id]; exists {
+		return &VertexError{ErrDuplicateVertex, id}
+	}
+
+	graph.sequence++
+	graph.vertices[id] = &vertex{v, true, graph.sequence}
+	graph.egress[id] = make(map[ID]*edge)
+	graph.ingress[id] = make(map[ID]*edge)
+	graph.invalidate()
+	for _, each := range graph.listeners {
+		each.vertexAdded(id)
+	}
+
+	return nil
+}
+
+// addEdge adds the edge without recording the change.
+func (graph *Graph) addEdge(from ID, to ID, weight float64, e interface{}) error {
 	if weight == math.Inf(-1) {
-		return fmt.Errorf("-inf weight is reserved for internal usage")
+		return &EdgeError{ErrReservedWeight, from, to}
 	}
 
 	if _, exists := graph.vertices[from]; !exists {
-		return fmt.Errorf("Vertex(from) %v is not found", from)
+		return &VertexError{ErrVertexNotFound, from}
 	}
 
 	if _, exists := graph.vertices[to]; !exists {
-		return fmt.Errorf("Vertex(to) %v is not found", to)
+		return &VertexError{ErrVertexNotFound, to}
 	}
 
 	if _, exists := graph.egress[from][to]; exists {
-		return fmt.Errorf("Edge from %v to %v is duplicate", from, to)
+		return &EdgeError{ErrDuplicateEdge, from, to}
 	}
 
 	graph.egress[from][to] = &edge{e, weight, true, false}
 	graph.ingress[to][from] = graph.egress[from][to]
+	graph.invalidate()
+	for _, each := range graph.listeners {
+		each.edgeAdded(from, to, weight)
+	}
 
 	return nil
 }
 
-// UpdateEdgeWeight updates the weight of the edge between vertices by the input ids.
-// Try to update an edge with -Inf weight will get an error.
-// Try to update an edge from or to a vertex not in the graph will get an error.
-// Try to update an edge between disconnected vertices will get an error.
-func (graph *Graph) UpdateEdgeWeight(from ID, to ID, weight float64) error {
+// updateEdgeWeight updates the weight of the edge without recording the change.
+func (graph *Graph) updateEdgeWeight(from ID, to ID, weight float64) error {
 	if weight == math.Inf(-1) {
-		return fmt.Errorf("-inf weight is reserved for internal usage")
+		return &EdgeError{ErrReservedWeight, from, to}
 	}
 
 	if _, exists := graph.vertices[from]; !exists {
-		return fmt.Errorf("Vertex(from) %v is not found", from)
+		return &VertexError{ErrVertexNotFound, from}
 	}
 
 	if _, exists := graph.vertices[to]; !exists {
-		return fmt.Errorf("Vertex(to) %v is not found", to)
+		return &VertexError{ErrVertexNotFound, to}
 	}
 
 	if edge, exists := graph.egress[from][to]; exists {
+		old := edge.weight
 		edge.weight = weight
+		graph.invalidate()
+		for _, each := range graph.listeners {
+			each.edgeUpdated(from, to, old, weight)
+		}
 		return nil
 	}
 
-	return fmt.Errorf("Edge from %v to %v is not found", from, to)
+	return &EdgeError{ErrEdgeNotFound, from, to}
 }
 
-// DeleteVertex deletes a vertex from the graph and gets the value of the vertex.
-// Try to delete a vertex not in the graph will get an nil.
-func (graph *Graph) DeleteVertex(id ID) interface{} {
+// deleteVertex deletes the vertex together with its edges without recording the change, and gets it, nil if there is no vertex.
+// The edges are deleted one by one before the vertex, so that the listeners see the edges go first.
+func (graph *Graph) deleteVertex(id ID) *vertex {
 	if vertex, exists := graph.vertices[id]; exists {
-		for to := range graph.egress[id] {
-			delete(graph.ingress[to], id)
+		for _, to := range graph.egressIDs(id) {
+			graph.deleteEdge(id, to)
 		}
-		for from := range graph.ingress[id] {
-			delete(graph.egress[from], id)
+		for _, from := range graph.ingressIDs(id) {
+			graph.deleteEdge(from, id)
 		}
 		delete(graph.egress, id)
 		delete(graph.ingress, id)
 		delete(graph.vertices, id)
+		graph.invalidate()
+		for _, each := range graph.listeners {
+			each.vertexDeleted(id)
+		}
 
-		return vertex.self
+		return vertex
 	}
 
 	return nil
 }
 
-// DeleteEdge deletes the edge between the vertices by the input id from the graph and gets the value of edge.
-// Try to delete an edge from or to a vertex not in the graph will get an error.
-// Try to delete an edge between disconnected vertices will get a nil.
-func (graph *Graph) DeleteEdge(from ID, to ID) interface{} {
-	if _, exists := graph.vertices[from]; !exists {
-		return nil
-	}
-
-	if _, exists := graph.vertices[to]; !exists {
+// deleteEdge deletes the edge between the vertices and gets it, nil if there is no edge.
+func (graph *Graph) deleteEdge(from ID, to ID) *edge {
+	edge, exists := graph.egress[from][to]
+	if !exists {
 		return nil
 	}
 
-	if edge, exists := graph.egress[from][to]; exists {
-		delete(graph.egress[from], to)
-		delete(graph.ingress[to], from)
-		return edge.self
+	delete(graph.egress[from], to)
+	delete(graph.ingress[to], from)
+	graph.invalidate()
+	for _, each := range graph.listeners {
+		each.edgeDeleted(from, to, edge.weight)
 	}
 
-	return nil
+	return edge
 }
 
 // AddVertexWithEdges adds a vertex value which implements Vertex interface.
 // AddVertexWithEdges adds edges connected to the vertex at the same time, due to the Vertex interface can get the Edges.
+// It is atomic, nothing is added if any edge is invalid.
 func (graph *Graph) AddVertexWithEdges(v Vertex) error {
-	if _, exists := graph.vertices[v.ID()]; exists {
-		return fmt.Errorf("Vertex %v is duplicate", v.ID())
-	}
-
-	graph.vertices[v.ID()] = &vertex{v, true}
-	graph.egress[v.ID()] = make(map[ID]*edge)
-	graph.ingress[v.ID()] = make(map[ID]*edge)
-
-	for _, eachEdge := range v.Edges() {
-		from, to, weight := eachEdge.Get()
-		if weight == math.Inf(-1) {
-			return fmt.Errorf("-inf weight is reserved for internal usage")
-		}
-		if from != v.ID() && to != v.ID() {
-			return fmt.Errorf("Edge from %v to %v is unrelated to the vertex %v", from, to, v.ID())
-		}
-
-		if _, exists := graph.egress[to]; !exists {
-			graph.egress[to] = make(map[ID]*edge)
-		}
-		if _, exists := graph.egress[from]; !exists {
-			graph.egress[from] = make(map[ID]*edge)
-		}
-		if _, exists := graph.ingress[from]; !exists {
-			graph.ingress[from] = make(map[ID]*edge)
-		}
-		if _, exists := graph.ingress[to]; !exists {
-			graph.ingress[to] = make(map[ID]*edge)
-		}
-
-		graph.egress[from][to] = &edge{eachEdge, weight, true, false}
-		graph.ingress[to][from] = graph.egress[from][to]
-	}
-
-	return nil
+	return graph.Batch(func(tx *Tx) error {
+		return tx.AddVertexWithEdges(v)
+	})
 }
 
 // CheckIntegrity checks if any edge connects to or from unknown vertex.
@@ -261,22 +389,22 @@ func (graph *Graph) AddVertexWithEdges(v Vertex) error {
 func (graph *Graph) CheckIntegrity() error {
 	for from, out := range graph.egress {
 		if _, exists := graph.vertices[from]; !exists {
-			return fmt.Errorf("Vertex %v is not found", from)
+			return &VertexError{ErrVertexNotFound, from}
 		}
 		for to := range out {
 			if _, exists := graph.vertices[to]; !exists {
-				return fmt.Errorf("Vertex %v is not found", to)
+				return &VertexError{ErrVertexNotFound, to}
 			}
 		}
 	}
 
 	for to, in := range graph.ingress {
 		if _, exists := graph.vertices[to]; !exists {
-			return fmt.Errorf("Vertex %v is not found", to)
+			return &VertexError{ErrVertexNotFound, to}
 		}
 		for from := range in {
 			if _, exists := graph.vertices[from]; !exists {
-				return fmt.Errorf("Vertex %v is not found", from)
+				return &VertexError{ErrVertexNotFound, from}
 			}
 		}
 	}
@@ -314,6 +442,7 @@ func (graph *Graph) GetPathWeight(path []ID) (totalWeight float64) {
 // DisableEdge disables the edge for further calculation.
 func (graph *Graph) DisableEdge(from, to ID) {
 	graph.egress[from][to].enable = false
+	graph.invalidate()
 }
 
 // DisableVertex disables the vertex for further calculation.
@@ -321,6 +450,7 @@ func (graph *Graph) DisableVertex(vertex ID) {
 	for _, edge := range graph.egress[vertex] {
 		edge.enable = false
 	}
+	graph.invalidate()
 }
 
 // DisablePath disables all the vertices in the path for further calculation.
@@ -330,6 +460,37 @@ func (graph *Graph) DisablePath(path []ID) {
 	}
 }
 
+// blocking records the vertices and edges excluded from a single calculation.
+// Unlike the disabled ones, they are not stored in the graph so calculations can run concurrently.
+// A blocked vertex blocks all the edges from it as DisableVertex does.
+type blocking struct {
+	vertices map[ID]bool
+	edges    map[ID]map[ID]bool
+}
+
+func newBlocking() *blocking {
+	return &blocking{make(map[ID]bool), make(map[ID]map[ID]bool)}
+}
+
+func (blocked *blocking) blockVertex(id ID) {
+	blocked.vertices[id] = true
+}
+
+func (blocked *blocking) blockEdge(from, to ID) {
+	if _, exists := blocked.edges[from]; !exists {
+		blocked.edges[from] = make(map[ID]bool)
+	}
+	blocked.edges[from][to] = true
+}
+
+func (blocked *blocking) blocks(from, to ID) bool {
+	if blocked == nil {
+		return false
+	}
+
+	return blocked.vertices[from] || blocked.edges[from][to]
+}
+
 // Reset enables all vertices and edges for further calculation.
 func (graph *Graph) Reset() {
 	for _, out := range graph.egress {
@@ -337,4 +498,80 @@ func (graph *Graph) Reset() {
 			edge.enable = true
 		}
 	}
+	graph.invalidate()
+}
+
+// vertexIDs gets the ids of all the vertices in the graph, in stable order in the deterministic mode.
+func (graph *Graph) vertexIDs() []ID {
+	ids := make([]ID, 0, len(graph.vertices))
+	for id := range graph.vertices {
+		ids = append(ids, id)
+	}
+
+	return graph.sortIDs(ids)
+}
+
+// egressIDs gets the ids of the vertices which the vertex connects to, in stable order in the deterministic mode.
+func (graph *Graph) egressIDs(from ID) []ID {
+	ids := make([]ID, 0, len(graph.egress[from]))
+	for to := range graph.egress[from] {
+		ids = append(ids, to)
+	}
+
+	return graph.sortIDs(ids)
+}
+
+// ingressIDs gets the ids of the vertices which connect to the vertex, in stable order in the deterministic mode.
+func (graph *Graph) ingressIDs(to ID) []ID {
+	ids := make([]ID, 0, len(graph.ingress[to]))
+	for from := range graph.ingress[to] {
+		ids = append(ids, from)
+	}
+
+	return graph.sortIDs(ids)
+}
+
+func (graph *Graph) sortIDs(ids []ID) []ID {
+	if graph.deterministic {
+		sort.Slice(ids, func(i, j int) bool {
+			return graph.before(ids[i], ids[j])
+		})
+	}
+
+	return ids
+}
+
+// before reports whether vertex a is ordered before vertex b in the deterministic mode.
+// Unknown vertices are ordered after all the known ones.
+func (graph *Graph) before(a, b ID) bool {
+	if graph.less != nil {
+		return graph.less(a, b)
+	}
+
+	vertexA, existsA := graph.vertices[a]
+	vertexB, existsB := graph.vertices[b]
+	if !existsA || !existsB {
+		return existsA
+	}
+
+	return vertexA.order < vertexB.order
+}
+
+// equalWeights reports whether the two weights are equal within the tolerance, the infinite weights are equal only to themselves.
+func (graph *Graph) equalWeights(a, b float64) bool {
+	return a == b || math.Abs(a-b) <= graph.tolerance
+}
+
+// pathBefore reports whether path a is lexicographically ordered before path b in the deterministic mode.
+func (graph *Graph) pathBefore(a, b []ID) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if graph.before(a[i], b[i]) {
+			return true
+		}
+		if graph.before(b[i], a[i]) {
+			return false
+		}
+	}
+
+	return len(a) < len(b)
 }
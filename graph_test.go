@@ -384,6 +384,72 @@ var _ = Describe("Tests of Graph structure", func() {
 			Expect(graph.GetPathWeight(path)).Should(BeEquivalentTo(15))
 		})
 	})
+
+	Context("deterministic mode tests", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertexWithEdges(&myVertex{"C", map[ID]float64{"D": 3, "E": 2}, map[ID]float64{}})
+			graph.AddVertexWithEdges(&myVertex{"D", map[ID]float64{"F": 4}, map[ID]float64{"C": 3, "E": 1}})
+			graph.AddVertexWithEdges(&myVertex{"E", map[ID]float64{"D": 1, "F": 2, "G": 3}, map[ID]float64{"C": 2}})
+			graph.AddVertexWithEdges(&myVertex{"F", map[ID]float64{"G": 2, "H": 1}, map[ID]float64{"D": 4, "E": 2}})
+			graph.AddVertexWithEdges(&myVertex{"G", map[ID]float64{"H": 2}, map[ID]float64{"E": 3, "F": 2}})
+			graph.AddVertexWithEdges(&myVertex{"H", map[ID]float64{}, map[ID]float64{"F": 1, "G": 2}})
+			graph.SetDeterministic(true)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph in deterministic mode, when call yen api repeatedly, then the paths with equal weights are always in insertion order", func() {
+			for i := 0; i < 20; i++ {
+				dist, path, err := graph.Yen("C", "H", 7)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(dist).Should(BeEquivalentTo([]float64{5, 7, 8, 8, 8, 11, 11}))
+				Expect(path).Should(BeEquivalentTo([][]ID{
+					{"C", "E", "F", "H"},
+					{"C", "E", "G", "H"},
+					{"C", "D", "F", "H"},
+					{"C", "E", "D", "F", "H"},
+					{"C", "E", "F", "G", "H"},
+					{"C", "D", "F", "G", "H"},
+					{"C", "E", "D", "F", "G", "H"},
+				}))
+			}
+		})
+
+		It("Given a graph in deterministic mode, when call dijkstra api repeatedly, then the predecessor with equal weights is always the first in insertion order", func() {
+			graph.UpdateEdgeWeight("C", "E", 3)
+			graph.UpdateEdgeWeight("E", "F", 4)
+			for i := 0; i < 20; i++ {
+				dist, prev, err := graph.Dijkstra("C")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(dist["F"]).Should(BeEquivalentTo(7))
+				Expect(prev["F"]).Should(BeEquivalentTo("D"))
+			}
+		})
+
+		It("Given a graph in deterministic mode with a custom order, when call yen and all shortest paths api, then the paths with equal weights are in the custom order", func() {
+			graph.SetOrder(func(a, b ID) bool {
+				return a.(string) > b.(string)
+			})
+			dist, path, err := graph.Yen("C", "H", 5)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist[2:]).Should(BeEquivalentTo([]float64{8, 8, 8}))
+			Expect(path[2:]).Should(BeEquivalentTo([][]ID{
+				{"C", "E", "F", "G", "H"},
+				{"C", "E", "D", "F", "H"},
+				{"C", "D", "F", "H"},
+			}))
+
+			graph.UpdateEdgeWeight("C", "E", 3)
+			graph.UpdateEdgeWeight("E", "F", 4)
+			graph.UpdateEdgeWeight("E", "G", 10)
+			_, paths, err := graph.AllShortestPaths("C", "H")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"C", "E", "F", "H"}, {"C", "D", "F", "H"}}))
+		})
+	})
 })
 
 type testVertex struct {
@@ -0,0 +1,487 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"unsafe"
+)
+
+// HubLabels is a distance oracle made up of a label of hubs for each vertex,
+// so that the distance between any two vertices is the shortest one through the hubs their labels share.
+// Queries can run concurrently, and answer on the graph as it was when the labels were built.
+// https://arxiv.org/abs/1304.4661
+type HubLabels struct {
+	ids   []ID
+	index map[ID]int32
+	// order is the vertices by the rank of hubs, the hubs in the labels are the ranks in ascending order.
+	order []int32
+	// out is the hubs each vertex reaches with the distances, and in is the hubs reaching each vertex with the distances.
+	out [][]hubEntry
+	in  [][]hubEntry
+	// next is the vertex after each vertex on the path to each hub in its out label, and prev is the vertex before each vertex on the path from each hub in its in label.
+	// They are nil if the labels are built without paths.
+	next [][]int32
+	prev [][]int32
+}
+
+type hubEntry struct {
+	hub  int32
+	dist float64
+}
+
+// HubLabelStats is the size of the hub labels.
+type HubLabelStats struct {
+	// Vertices is the number of the vertices.
+	Vertices int
+	// Entries is the total number of the hubs in all the labels.
+	Entries int
+	// AverageLabelSize is the average number of the hubs in the out and in labels of a vertex.
+	AverageLabelSize float64
+	// MaxLabelSize is the max number of the hubs in the out or in label of a vertex.
+	MaxLabelSize int
+	// Bytes is the approximate memory used by the labels.
+	Bytes int64
+}
+
+// BuildHubLabels builds the hub labels by pruned landmark labeling, in which the vertices of higher degrees become hubs first.
+// Each hub runs a Dijkstra forward and backward, pruned at the vertices whose distances are already covered by the labels.
+// The paths can be reconstructed from the labels built with paths, at the cost of an extra vertex per hub in the labels.
+// Try to build the labels of a graph with negative edges will get ErrNegativeWeight.
+func (graph *Graph) BuildHubLabels(withPaths bool) (*HubLabels, error) {
+	compact := graph.compact()
+	if compact.err != nil {
+		return nil, compact.err
+	}
+
+	size := len(compact.ids)
+	labels := &HubLabels{
+		ids:   compact.ids,
+		index: compact.index,
+		order: make([]int32, size),
+		out:   make([][]hubEntry, size),
+		in:    make([][]hubEntry, size),
+	}
+	if withPaths {
+		labels.next = make([][]int32, size)
+		labels.prev = make([][]int32, size)
+	}
+
+	reversed := compact.reverse()
+	degrees := make([]int32, size)
+	for v := range compact.ids {
+		degrees[v] = compact.offsets[v+1] - compact.offsets[v] + reversed.offsets[v+1] - reversed.offsets[v]
+		labels.order[v] = int32(v)
+	}
+	sort.SliceStable(labels.order, func(i, j int) bool {
+		return degrees[labels.order[i]] > degrees[labels.order[j]]
+	})
+
+	hubDist := make([]float64, size)
+	for i := range hubDist {
+		hubDist[i] = math.Inf(1)
+	}
+	for rank, hub := range labels.order {
+		labels.prune(compact, int32(rank), hub, labels.out[hub], hubDist, labels.in, labels.prev)
+		labels.prune(reversed, int32(rank), hub, labels.in[hub], hubDist, labels.out, labels.next)
+	}
+
+	return labels, nil
+}
+
+// prune runs the pruned Dijkstra from the hub along the compact graph, and adds the hub into the labels of the vertices not covered.
+// The hub distances is a scratch array of all +Inf, used to look up the label of the hub on the other side.
+func (labels *HubLabels) prune(compact *compactGraph, rank, hub int32, hubLabel []hubEntry, hubDist []float64, targets [][]hubEntry, parents [][]int32) {
+	for _, entry := range hubLabel {
+		hubDist[entry.hub] = entry.dist
+	}
+	defer func() {
+		for _, entry := range hubLabel {
+			hubDist[entry.hub] = math.Inf(1)
+		}
+	}()
+
+	dist := map[int32]float64{hub: 0}
+	prev := map[int32]int32{hub: -1}
+	queue := NewBinaryHeap(0)
+	queue.Push(int(hub), 0)
+	for queue.Len() != 0 {
+		vertex, key := queue.Pop()
+		min := int32(vertex)
+		if key > dist[min] {
+			continue
+		}
+		covered := false
+		for _, entry := range targets[min] {
+			if hubDist[entry.hub]+entry.dist <= key {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		targets[min] = append(targets[min], hubEntry{rank, key})
+		if parents != nil {
+			parents[min] = append(parents[min], prev[min])
+		}
+		for e := compact.offsets[min]; e < compact.offsets[min+1]; e++ {
+			to := compact.targets[e]
+			if d, exists := dist[to]; !exists || key+compact.weights[e] < d {
+				dist[to] = key + compact.weights[e]
+				prev[to] = min
+				queue.Push(int(to), dist[to])
+			}
+		}
+	}
+}
+
+// Distance gets the shortest distance between two vertices, +Inf if the destination is unreachable.
+// Try to query a vertex not in the labels will get an error.
+func (labels *HubLabels) Distance(source, destination ID) (float64, error) {
+	dist, _, _, err := labels.query(source, destination)
+	return dist, err
+}
+
+// ShortestPath gets the shortest path between two vertices, +Inf and a nil path if the destination is unreachable.
+// The path is nil if the labels are built without paths, and ErrCorruptData is returned if the paths do not lead to the hub.
+// Try to query a vertex not in the labels will get an error.
+func (labels *HubLabels) ShortestPath(source, destination ID) (dist float64, path []ID, err error) {
+	dist, s, t, err := labels.query(source, destination)
+	if err != nil || math.IsInf(dist, 1) || labels.next == nil {
+		return dist, nil, err
+	}
+
+	hub := labels.bestHub(s, t)
+	if path, err = labels.walk(path, s, hub, labels.out, labels.next); err != nil {
+		return math.Inf(1), nil, err
+	}
+	length := len(path)
+	if path, err = labels.walk(path, t, hub, labels.in, labels.prev); err != nil {
+		return math.Inf(1), nil, err
+	}
+	path = append(path, labels.ids[labels.order[hub]])
+	for i, j := length, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return dist, path, nil
+}
+
+// walk appends the vertices from the vertex along the parents until the hub, the hub itself is not appended.
+// The decoded labels are validated, but the walk is checked as well so that it never goes out of range nor loops forever.
+func (labels *HubLabels) walk(path []ID, from, hub int32, label [][]hubEntry, parents [][]int32) ([]ID, error) {
+	vertex := labels.order[hub]
+	for steps := 0; from != vertex; steps++ {
+		i := labels.find(label[from], hub)
+		if steps >= len(labels.ids) || i == len(label[from]) || label[from][i].hub != hub || i >= len(parents[from]) ||
+			parents[from][i] < 0 || int(parents[from][i]) >= len(labels.ids) {
+			return nil, &VertexError{ErrCorruptData, labels.ids[from]}
+		}
+		path = append(path, labels.ids[from])
+		from = parents[from][i]
+	}
+
+	return path, nil
+}
+
+// Stats gets the size of the labels.
+func (labels *HubLabels) Stats() HubLabelStats {
+	stats := HubLabelStats{Vertices: len(labels.ids)}
+	for v := range labels.ids {
+		stats.Entries += len(labels.out[v]) + len(labels.in[v])
+		if len(labels.out[v]) > stats.MaxLabelSize {
+			stats.MaxLabelSize = len(labels.out[v])
+		}
+		if len(labels.in[v]) > stats.MaxLabelSize {
+			stats.MaxLabelSize = len(labels.in[v])
+		}
+	}
+	if stats.Vertices != 0 {
+		stats.AverageLabelSize = float64(stats.Entries) / float64(stats.Vertices)
+	}
+
+	// each vertex has two labels, and two more for the paths, whose headers are counted together with the entries.
+	entry, header := int64(unsafe.Sizeof(hubEntry{})), int64(unsafe.Sizeof([]hubEntry{}))*2
+	if labels.next != nil {
+		entry += int64(unsafe.Sizeof(int32(0)))
+		header *= 2
+	}
+	stats.Bytes = int64(stats.Entries)*entry + int64(stats.Vertices)*header
+
+	return stats
+}
+
+func (labels *HubLabels) query(source, destination ID) (dist float64, s, t int32, err error) {
+	var exists bool
+	if s, exists = labels.index[source]; !exists {
+		return math.Inf(1), -1, -1, &VertexError{ErrVertexNotFound, source}
+	}
+	if t, exists = labels.index[destination]; !exists {
+		return math.Inf(1), -1, -1, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	hub := labels.bestHub(s, t)
+	if hub < 0 {
+		return math.Inf(1), s, t, nil
+	}
+
+	return labels.out[s][labels.find(labels.out[s], hub)].dist + labels.in[t][labels.find(labels.in[t], hub)].dist, s, t, nil
+}
+
+// bestHub gets the rank of the hub on the shortest path by merging the two sorted labels, -1 if there is no common hub.
+func (labels *HubLabels) bestHub(s, t int32) int32 {
+	out, in := labels.out[s], labels.in[t]
+	best, hub := math.Inf(1), int32(-1)
+	for i, j := 0, 0; i < len(out) && j < len(in); {
+		switch {
+		case out[i].hub < in[j].hub:
+			i++
+		case out[i].hub > in[j].hub:
+			j++
+		default:
+			if d := out[i].dist + in[j].dist; d < best {
+				best, hub = d, out[i].hub
+			}
+			i++
+			j++
+		}
+	}
+
+	return hub
+}
+
+// find gets the position of the hub in the sorted label.
+func (labels *HubLabels) find(label []hubEntry, hub int32) int {
+	return sort.Search(len(label), func(i int) bool {
+		return label[i].hub >= hub
+	})
+}
+
+// hubLabelsData is the exported form of the hub labels for encoding.
+type hubLabelsData struct {
+	IDs      []ID
+	Order    []int32
+	OutHubs  [][]int32
+	OutDists [][]float64
+	InHubs   [][]int32
+	InDists  [][]float64
+	Next     [][]int32
+	Prev     [][]int32
+}
+
+// MarshalBinary encodes the labels by gob, the types of the vertex ids other than the basic ones must be registered by gob.Register.
+func (labels *HubLabels) MarshalBinary() ([]byte, error) {
+	data := hubLabelsData{
+		IDs:      labels.ids,
+		Order:    labels.order,
+		OutHubs:  make([][]int32, len(labels.ids)),
+		OutDists: make([][]float64, len(labels.ids)),
+		InHubs:   make([][]int32, len(labels.ids)),
+		InDists:  make([][]float64, len(labels.ids)),
+		Next:     labels.next,
+		Prev:     labels.prev,
+	}
+	for v := range labels.ids {
+		data.OutHubs[v], data.OutDists[v] = splitLabel(labels.out[v])
+		data.InHubs[v], data.InDists[v] = splitLabel(labels.in[v])
+	}
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(&data); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the labels encoded by MarshalBinary.
+// Try to decode the labels truncated or inconsistent, including the paths not leading to their hubs, will get ErrCorruptData.
+func (labels *HubLabels) UnmarshalBinary(encoded []byte) error {
+	var data hubLabelsData
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&data); err != nil {
+		return err
+	}
+
+	if err := data.validate(); err != nil {
+		return err
+	}
+
+	size := len(data.IDs)
+	*labels = HubLabels{
+		ids:   data.IDs,
+		index: make(map[ID]int32, size),
+		order: data.Order,
+		out:   make([][]hubEntry, size),
+		in:    make([][]hubEntry, size),
+		next:  data.Next,
+		prev:  data.Prev,
+	}
+	for v, id := range data.IDs {
+		labels.index[id] = int32(v)
+		labels.out[v] = joinLabel(data.OutHubs[v], data.OutDists[v])
+		labels.in[v] = joinLabel(data.InHubs[v], data.InDists[v])
+	}
+
+	return nil
+}
+
+// validate checks the decoded data is consistent, so that the queries on the labels never go out of range.
+func (data *hubLabelsData) validate() error {
+	size := len(data.IDs)
+	if len(data.Order) != size || len(data.OutHubs) != size || len(data.OutDists) != size ||
+		len(data.InHubs) != size || len(data.InDists) != size {
+		return fmt.Errorf("%w: the labels do not match %d vertices", ErrCorruptData, size)
+	}
+	if (data.Next == nil) != (data.Prev == nil) || data.Next != nil && (len(data.Next) != size || len(data.Prev) != size) {
+		return fmt.Errorf("%w: the paths do not match %d vertices", ErrCorruptData, size)
+	}
+
+	ids := make(map[ID]bool, size)
+	for _, id := range data.IDs {
+		if ids[id] {
+			return fmt.Errorf("%w: vertex %v is duplicate", ErrCorruptData, id)
+		}
+		ids[id] = true
+	}
+	ranked := make([]bool, size)
+	for _, v := range data.Order {
+		if v < 0 || int(v) >= size || ranked[v] {
+			return fmt.Errorf("%w: the order of the hubs is not a permutation", ErrCorruptData)
+		}
+		ranked[v] = true
+	}
+
+	for v := 0; v < size; v++ {
+		var next, prev []int32
+		if data.Next != nil {
+			next, prev = data.Next[v], data.Prev[v]
+		}
+		if err := validateLabel(data.OutHubs[v], data.OutDists[v], next, data.Next != nil, size); err != nil {
+			return fmt.Errorf("%w: the out label of vertex %v %v", ErrCorruptData, data.IDs[v], err)
+		}
+		if err := validateLabel(data.InHubs[v], data.InDists[v], prev, data.Next != nil, size); err != nil {
+			return fmt.Errorf("%w: the in label of vertex %v %v", ErrCorruptData, data.IDs[v], err)
+		}
+	}
+
+	if data.Next != nil {
+		if err := validatePaths(data.OutHubs, data.OutDists, data.Next, data.Order); err != nil {
+			return fmt.Errorf("%w: the out labels %v", ErrCorruptData, err)
+		}
+		if err := validatePaths(data.InHubs, data.InDists, data.Prev, data.Order); err != nil {
+			return fmt.Errorf("%w: the in labels %v", ErrCorruptData, err)
+		}
+	}
+
+	return nil
+}
+
+// validateLabel checks the hubs of a label are ranks in ascending order, with the distances and the vertices on the paths if any.
+func validateLabel(hubs []int32, dists []float64, parents []int32, withPaths bool, size int) error {
+	if len(dists) != len(hubs) {
+		return errors.New("has the distances not matching the hubs")
+	}
+	if withPaths && len(parents) != len(hubs) {
+		return errors.New("has the paths not matching the hubs")
+	}
+	for i, hub := range hubs {
+		if hub < 0 || int(hub) >= size || i > 0 && hub <= hubs[i-1] {
+			return errors.New("has the hubs out of range or order")
+		}
+		if math.IsNaN(dists[i]) || dists[i] < 0 {
+			return errors.New("has invalid distances")
+		}
+		if withPaths && (parents[i] < -1 || int(parents[i]) >= size) {
+			return errors.New("has the paths out of range")
+		}
+	}
+
+	return nil
+}
+
+// validatePaths checks the parents of each label entry lead to its hub through the vertices having the same hub,
+// with the distances not growing and the hops left to the hub strictly shrinking, so that there is no cycle.
+// The labels must have passed validateLabel.
+func validatePaths(hubs [][]int32, dists [][]float64, parents [][]int32, order []int32) error {
+	// hops is the number of the vertices to the hub along the parents of each entry, 0 if unknown and -1 while being followed.
+	hops := make([][]int32, len(hubs))
+	for v := range hubs {
+		hops[v] = make([]int32, len(hubs[v]))
+	}
+
+	type entry struct {
+		vertex int32
+		i      int
+	}
+	var chain []entry
+	for v := range hubs {
+		for i := range hubs[v] {
+			chain = chain[:0]
+			known := int32(0)
+			for each := (entry{int32(v), i}); ; {
+				if hops[each.vertex][each.i] > 0 {
+					known = hops[each.vertex][each.i]
+					break
+				}
+				if hops[each.vertex][each.i] < 0 {
+					return fmt.Errorf("have a cycle at vertex %d", each.vertex)
+				}
+				hops[each.vertex][each.i] = -1
+				chain = append(chain, each)
+
+				hub, parent := hubs[each.vertex][each.i], parents[each.vertex][each.i]
+				if each.vertex == order[hub] {
+					if parent != -1 {
+						return fmt.Errorf("have the hub %d with a parent", each.vertex)
+					}
+					break
+				}
+				if parent < 0 {
+					return fmt.Errorf("have vertex %d not reaching hub %d", each.vertex, order[hub])
+				}
+				j := sort.Search(len(hubs[parent]), func(k int) bool {
+					return hubs[parent][k] >= hub
+				})
+				if j == len(hubs[parent]) || hubs[parent][j] != hub {
+					return fmt.Errorf("have vertex %d whose parent %d lacks hub %d", each.vertex, parent, order[hub])
+				}
+				if dists[parent][j] > dists[each.vertex][each.i] {
+					return fmt.Errorf("have vertex %d farther from hub %d than its parent %d", each.vertex, order[hub], parent)
+				}
+				each = entry{parent, j}
+			}
+			for k := len(chain) - 1; k >= 0; k-- {
+				known++
+				hops[chain[k].vertex][chain[k].i] = known
+			}
+		}
+	}
+
+	return nil
+}
+
+func splitLabel(label []hubEntry) (hubs []int32, dists []float64) {
+	hubs = make([]int32, len(label))
+	dists = make([]float64, len(label))
+	for i, entry := range label {
+		hubs[i], dists[i] = entry.hub, entry.dist
+	}
+
+	return
+}
+
+func joinLabel(hubs []int32, dists []float64) []hubEntry {
+	label := make([]hubEntry, len(hubs))
+	for i := range hubs {
+		label[i] = hubEntry{hubs[i], dists[i]}
+	}
+
+	return label
+}
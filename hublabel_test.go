@@ -0,0 +1,272 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var _ = Describe("Tests of HubLabels", func() {
+	var (
+		graph *Graph
+	)
+
+	expectSameAsDijkstra := func(graph *Graph, labels *HubLabels, withPaths bool) {
+		for source := range graph.vertices {
+			expectedDist, _, _ := graph.Dijkstra(source)
+			for destination, expected := range expectedDist {
+				dist, err := labels.Distance(source, destination)
+				Expect(err).ShouldNot(HaveOccurred())
+				dist, path, err := labels.ShortestPath(source, destination)
+				Expect(err).ShouldNot(HaveOccurred())
+				if math.IsInf(expected, 1) {
+					Expect(dist).Should(Equal(expected), "%v to %v", source, destination)
+					Expect(path).Should(BeNil())
+					continue
+				}
+				Expect(dist).Should(BeNumerically("~", expected, 1e-9), "%v to %v", source, destination)
+				if !withPaths {
+					Expect(path).Should(BeNil())
+					continue
+				}
+				Expect(path[0]).Should(Equal(source))
+				Expect(path[len(path)-1]).Should(Equal(destination))
+				Expect(graph.GetPathWeight(path)).Should(BeNumerically("~", expected, 1e-9))
+			}
+		}
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "T", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with negative edge, when build hub labels, then get ErrNegativeWeight.", func() {
+			graph.UpdateEdgeWeight("S", "T", -1)
+			labels, err := graph.BuildHubLabels(true)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(labels).Should(BeNil())
+		})
+
+		It("Given hub labels, when query with non-existed vertex, then get ErrVertexNotFound.", func() {
+			labels, err := graph.BuildHubLabels(true)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dist, err := labels.Distance("X", "T")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			_, path, err := labels.ShortestPath("S", "X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(path).Should(BeNil())
+		})
+
+		It("Given hub labels, when query an unreachable vertex or the source itself, then get +Inf or 0.", func() {
+			labels, _ := graph.BuildHubLabels(true)
+
+			dist, path, err := labels.ShortestPath("T", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+
+			dist, path, err = labels.ShortestPath("S", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeZero())
+			Expect(path).Should(Equal([]ID{"S"}))
+		})
+
+		It("Given broken bytes, when unmarshal hub labels, then get an error.", func() {
+			var labels HubLabels
+			Expect(labels.UnmarshalBinary([]byte("broken"))).ShouldNot(Succeed())
+		})
+
+		It("Given truncated or inconsistent labels, when unmarshal them, then get an error instead of panics on queries.", func() {
+			graph.SetDeterministic(true)
+			built, err := graph.BuildHubLabels(true)
+			Expect(err).ShouldNot(HaveOccurred())
+			encoded, err := built.MarshalBinary()
+			Expect(err).ShouldNot(HaveOccurred())
+			var labels HubLabels
+			Expect(labels.UnmarshalBinary(encoded[:len(encoded)/2])).ShouldNot(Succeed())
+
+			for i, corrupt := range []func(data *hubLabelsData){
+				func(data *hubLabelsData) { data.OutHubs = data.OutHubs[:1] },
+				func(data *hubLabelsData) { data.InDists = append(data.InDists, nil) },
+				func(data *hubLabelsData) { data.OutDists[0] = data.OutDists[0][1:] },
+				func(data *hubLabelsData) { data.InHubs[1][0] = 2 },
+				func(data *hubLabelsData) { data.OutHubs[0][0] = -1 },
+				func(data *hubLabelsData) { data.InHubs[1] = []int32{1, 0} },
+				func(data *hubLabelsData) { data.OutDists[0][0] = math.NaN() },
+				func(data *hubLabelsData) { data.Order = []int32{0, 0} },
+				func(data *hubLabelsData) { data.Order = []int32{0} },
+				func(data *hubLabelsData) { data.IDs[1] = data.IDs[0] },
+				func(data *hubLabelsData) { data.Next = nil },
+				func(data *hubLabelsData) { data.Prev[1] = nil },
+				func(data *hubLabelsData) { data.Next[0][0] = 5 },
+			} {
+				var data hubLabelsData
+				Expect(gob.NewDecoder(bytes.NewReader(encoded)).Decode(&data)).Should(Succeed())
+				// T has both S and itself as the hubs of its in label, so that the order of the hubs can be broken.
+				Expect(data.InHubs[1]).Should(Equal([]int32{0, 1}))
+				corrupt(&data)
+				var buffer bytes.Buffer
+				Expect(gob.NewEncoder(&buffer).Encode(&data)).Should(Succeed())
+				err := labels.UnmarshalBinary(buffer.Bytes())
+				Expect(errors.Is(err, ErrCorruptData)).Should(BeTrue(), "corruption %d: %v", i, err)
+			}
+		})
+		It("Given labels with a bad parent, when unmarshal them, then get ErrCorruptData, and the paths of the labels tampered in memory are not walked blindly.", func() {
+			chain := NewGraph()
+			chain.SetDeterministic(true)
+			for i := 0; i < 5; i++ {
+				chain.AddVertex(i, nil)
+			}
+			for i := 0; i < 4; i++ {
+				chain.AddEdge(i, i+1, 1, nil)
+			}
+			built, err := chain.BuildHubLabels(true)
+			Expect(err).ShouldNot(HaveOccurred())
+			encoded, err := built.MarshalBinary()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// the first entry in the out labels whose hub is not the vertex itself, with a parent leading to the hub.
+			var v, i int
+			var data hubLabelsData
+			Expect(gob.NewDecoder(bytes.NewReader(encoded)).Decode(&data)).Should(Succeed())
+			for v = range data.OutHubs {
+				for i = range data.OutHubs[v] {
+					if int(data.Order[data.OutHubs[v][i]]) != v {
+						break
+					}
+				}
+				if i < len(data.OutHubs[v]) && int(data.Order[data.OutHubs[v][i]]) != v {
+					break
+				}
+			}
+			Expect(data.Next[v][i]).ShouldNot(Equal(int32(-1)))
+
+			for j, corrupt := range []func(data *hubLabelsData){
+				func(data *hubLabelsData) { data.Next[v][i] = -1 },
+				func(data *hubLabelsData) { data.Next[v][i] = int32(v) },
+				func(data *hubLabelsData) {
+					parent := data.Next[v][i]
+					for k, hub := range data.OutHubs[parent] {
+						if hub == data.OutHubs[v][i] {
+							data.Next[parent][k] = int32(v)
+						}
+					}
+				},
+				func(data *hubLabelsData) {
+					parent := data.Next[v][i]
+					data.OutHubs[parent], data.OutDists[parent], data.Next[parent] = nil, nil, nil
+				},
+			} {
+				var data hubLabelsData
+				Expect(gob.NewDecoder(bytes.NewReader(encoded)).Decode(&data)).Should(Succeed())
+				corrupt(&data)
+				var buffer bytes.Buffer
+				Expect(gob.NewEncoder(&buffer).Encode(&data)).Should(Succeed())
+				var labels HubLabels
+				err := labels.UnmarshalBinary(buffer.Bytes())
+				Expect(errors.Is(err, ErrCorruptData)).Should(BeTrue(), "corruption %d: %v", j, err)
+			}
+
+			var labels HubLabels
+			Expect(labels.UnmarshalBinary(encoded)).Should(Succeed())
+			for j := range labels.next[v] {
+				labels.next[v][j] = int32(v)
+			}
+			_, path, err := labels.ShortestPath(v, 4)
+			Expect(errors.Is(err, ErrCorruptData)).Should(BeTrue())
+			Expect(path).Should(BeNil())
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(6))
+			graph = NewGraph()
+			graph.SetDeterministic(true)
+			for i := 0; i < 150; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 150; i++ {
+				for j := 0; j < 3; j++ {
+					graph.AddEdge(i, random.Intn(150), float64(random.Intn(20)), nil)
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given hub labels with or without paths, when query, then get the same distances as dijkstra.", func() {
+			for _, withPaths := range []bool{true, false} {
+				labels, err := graph.BuildHubLabels(withPaths)
+				Expect(err).ShouldNot(HaveOccurred())
+				expectSameAsDijkstra(graph, labels, withPaths)
+			}
+		})
+
+		It("Given hub labels, when marshal and unmarshal them, then the decoded labels answer the same.", func() {
+			for _, withPaths := range []bool{true, false} {
+				labels, _ := graph.BuildHubLabels(withPaths)
+				encoded, err := labels.MarshalBinary()
+				Expect(err).ShouldNot(HaveOccurred())
+
+				var decoded HubLabels
+				Expect(decoded.UnmarshalBinary(encoded)).Should(Succeed())
+				Expect(decoded.Stats()).Should(Equal(labels.Stats()))
+				expectSameAsDijkstra(graph, &decoded, withPaths)
+			}
+		})
+
+		It("Given hub labels, when get the stats, then get the sizes of the labels.", func() {
+			labels, _ := graph.BuildHubLabels(true)
+			stats := labels.Stats()
+			Expect(stats.Vertices).Should(Equal(150))
+
+			entries, max := 0, 0
+			for v := range labels.ids {
+				entries += len(labels.out[v]) + len(labels.in[v])
+				if len(labels.out[v]) > max {
+					max = len(labels.out[v])
+				}
+				if len(labels.in[v]) > max {
+					max = len(labels.in[v])
+				}
+			}
+			Expect(stats.Entries).Should(Equal(entries))
+			Expect(stats.MaxLabelSize).Should(Equal(max))
+			Expect(stats.AverageLabelSize).Should(BeNumerically("~", float64(entries)/150, 1e-9))
+			Expect(stats.Bytes).Should(BeNumerically(">", labels.Stats().Entries*16))
+
+			withoutPaths, _ := graph.BuildHubLabels(false)
+			Expect(withoutPaths.Stats().Entries).Should(Equal(stats.Entries))
+			Expect(withoutPaths.Stats().Bytes).Should(BeNumerically("<", stats.Bytes))
+		})
+	})
+})
+
+func BenchmarkHubLabels(b *testing.B) {
+	labels, _ := newGridGraph(50).BuildHubLabels(false)
+	random := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		labels.Distance(random.Intn(2500), random.Intn(2500))
+	}
+}
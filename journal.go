@@ -0,0 +1,112 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+// journal records the changes of the graph to undo and redo them.
+type journal struct {
+	// entries are the changes of each mutation or batch, the ones before the position are done and the rest are undone.
+	entries  [][]change
+	position int
+	// checkpoints are the positions by names.
+	checkpoints map[string]int
+}
+
+// EnableJournal starts recording the changes of the graph, so that they can be undone and redone.
+// Each mutation of the graph or each batch is recorded as one entry. Disabling and enabling edges and vertices are not recorded.
+func (graph *Graph) EnableJournal() {
+	if graph.journal == nil {
+		graph.journal = &journal{checkpoints: make(map[string]int)}
+	}
+}
+
+// DisableJournal stops recording the changes, the recorded ones and the checkpoints are discarded.
+func (graph *Graph) DisableJournal() {
+	graph.journal = nil
+}
+
+// Version gets the version of the graph, which increases by one after each mutation, batch, undo or redo changing the graph.
+// The version of a graph never goes back, so the results of the queries tagged with it are never mixed up.
+func (graph *Graph) Version() uint64 {
+	return graph.version
+}
+
+// Undo undoes the last entry of the journal, false if the journal is not enabled or there is nothing to undo.
+// The observers are notified of the reverse changes.
+func (graph *Graph) Undo() bool {
+	journal := graph.journal
+	if journal == nil || journal.position == 0 {
+		return false
+	}
+
+	journal.position--
+	changes := journal.entries[journal.position]
+	for i := len(changes) - 1; i >= 0; i-- {
+		changes[i].undo()
+	}
+	graph.version++
+
+	return true
+}
+
+// Redo redoes the last entry undone, false if the journal is not enabled or there is nothing to redo.
+// The entries undone are discarded once a new change is recorded.
+func (graph *Graph) Redo() bool {
+	journal := graph.journal
+	if journal == nil || journal.position == len(journal.entries) {
+		return false
+	}
+
+	for _, each := range journal.entries[journal.position] {
+		each.redo()
+	}
+	journal.position++
+	graph.version++
+
+	return true
+}
+
+// Checkpoint names the current state of the journal, to undo or redo back to it by RestoreCheckpoint later.
+// The checkpoint of the same name is moved.
+// Try to set a checkpoint without the journal enabled will get ErrJournalDisabled.
+func (graph *Graph) Checkpoint(name string) error {
+	if graph.journal == nil {
+		return ErrJournalDisabled
+	}
+
+	graph.journal.checkpoints[name] = graph.journal.position
+	return nil
+}
+
+// RestoreCheckpoint undoes or redoes the entries of the journal back to the checkpoint, the checkpoint is kept.
+// Try to restore a checkpoint without the journal enabled will get ErrJournalDisabled.
+// Try to restore a checkpoint not set, or discarded together with the entries undone, will get ErrCheckpointNotFound.
+func (graph *Graph) RestoreCheckpoint(name string) error {
+	journal := graph.journal
+	if journal == nil {
+		return ErrJournalDisabled
+	}
+	position, exists := journal.checkpoints[name]
+	if !exists {
+		return ErrCheckpointNotFound
+	}
+
+	for journal.position > position {
+		graph.Undo()
+	}
+	for journal.position < position {
+		graph.Redo()
+	}
+
+	return nil
+}
+
+// record appends the entry after the position, the entries undone and the checkpoints among them are discarded.
+func (journal *journal) record(changes []change) {
+	for name, position := range journal.checkpoints {
+		if position > journal.position {
+			delete(journal.checkpoints, name)
+		}
+	}
+	journal.entries = append(journal.entries[:journal.position], changes)
+	journal.position++
+}
@@ -0,0 +1,217 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of journal", func() {
+	var (
+		graph  *Graph
+		events []string
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.EnableJournal()
+		events = nil
+		graph.OnEdgeAdded(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge added ", from, to, weight))
+		})
+		graph.OnEdgeWeightUpdated(func(from, to ID, old, weight float64) {
+			events = append(events, fmt.Sprint("edge updated ", from, to, old, weight))
+		})
+		graph.OnEdgeDeleted(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge deleted ", from, to, weight))
+		})
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	weightOf := func(from, to ID) float64 {
+		weight, _ := graph.GetEdgeWeight(from, to)
+		return weight
+	}
+
+	Context("exception test", func() {
+		It("Given a graph without the journal, when undo, redo or use checkpoints, then nothing changes.", func() {
+			graph.DisableJournal()
+			graph.AddVertex("S", nil)
+			Expect(graph.Undo()).Should(BeFalse())
+			Expect(graph.Redo()).Should(BeFalse())
+			Expect(errors.Is(graph.Checkpoint("start"), ErrJournalDisabled)).Should(BeTrue())
+			Expect(errors.Is(graph.RestoreCheckpoint("start"), ErrJournalDisabled)).Should(BeTrue())
+			Expect(graph.vertices).Should(HaveKey("S"))
+		})
+
+		It("Given a graph with the journal, when undo or redo beyond the entries, then get false.", func() {
+			Expect(graph.Undo()).Should(BeFalse())
+			graph.AddVertex("S", nil)
+			Expect(graph.Redo()).Should(BeFalse())
+			Expect(graph.Undo()).Should(BeTrue())
+			Expect(graph.Undo()).Should(BeFalse())
+		})
+
+		It("Given a graph with the journal, when the changes fail, then nothing is recorded.", func() {
+			graph.AddVertex("S", nil)
+			version := graph.Version()
+			Expect(graph.AddVertex("S", nil)).Should(HaveOccurred())
+			Expect(graph.AddEdge("S", "T", 1, nil)).Should(HaveOccurred())
+			Expect(graph.DeleteEdge("S", "T")).Should(BeNil())
+			Expect(graph.DeleteVertex("T")).Should(BeNil())
+			graph.Batch(func(tx *Tx) error {
+				tx.AddVertex("T", nil)
+				return errors.New("failure")
+			})
+			Expect(graph.Version()).Should(Equal(version))
+			Expect(graph.journal.entries).Should(HaveLen(1))
+		})
+
+		It("Given a graph with the journal, when restore a checkpoint unknown or discarded, then get an error.", func() {
+			Expect(errors.Is(graph.RestoreCheckpoint("unknown"), ErrCheckpointNotFound)).Should(BeTrue())
+			graph.AddVertex("S", nil)
+			graph.Checkpoint("S added")
+			graph.Undo()
+			graph.AddVertex("T", nil)
+			Expect(errors.Is(graph.RestoreCheckpoint("S added"), ErrCheckpointNotFound)).Should(BeTrue())
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given a graph with the journal, when undo and redo all the changes, then the graph goes back and forth with the observers notified.", func() {
+			graph.AddVertex("S", "S")
+			graph.AddVertex("T", "T")
+			graph.AddEdge("S", "T", 1, "ST")
+			graph.AddEdge("T", "S", 2, "TS")
+			graph.UpdateEdgeWeight("S", "T", 3)
+			graph.DeleteEdge("T", "S")
+			graph.DeleteVertex("T")
+			events = nil
+
+			Expect(graph.Undo()).Should(BeTrue())
+			Expect(graph.GetVertex("T")).Should(Equal("T"))
+			Expect(weightOf("S", "T")).Should(BeEquivalentTo(3))
+			Expect(graph.Undo()).Should(BeTrue())
+			Expect(graph.GetEdge("T", "S")).Should(Equal("TS"))
+			Expect(graph.Undo()).Should(BeTrue())
+			Expect(weightOf("S", "T")).Should(BeEquivalentTo(1))
+			for graph.Undo() {
+			}
+			Expect(graph.vertices).Should(BeEmpty())
+			Expect(graph.egress).Should(BeEmpty())
+			Expect(graph.ingress).Should(BeEmpty())
+
+			for graph.Redo() {
+			}
+			Expect(graph.vertices).Should(HaveLen(1))
+			Expect(graph.GetVertex("S")).Should(Equal("S"))
+			Expect(graph.egress["S"]).Should(BeEmpty())
+			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+			Expect(events).Should(Equal([]string{
+				"edge added ST3",
+				"edge added TS2",
+				"edge updated ST3 1",
+				"edge deleted TS2",
+				"edge deleted ST1",
+				"edge added ST1",
+				"edge added TS2",
+				"edge updated ST1 3",
+				"edge deleted TS2",
+				"edge deleted ST3",
+			}))
+		})
+
+		It("Given a graph with the journal, when undo a batch, then all the changes of the batch are undone together.", func() {
+			graph.AddVertex("S", nil)
+			graph.Batch(func(tx *Tx) error {
+				tx.AddVertex("T", nil)
+				tx.AddEdge("S", "T", 1, nil)
+				return tx.AddVertexWithEdges(&myVertex{"A", map[ID]float64{"T": 2, "X": 1}, map[ID]float64{"S": 1}})
+			})
+			Expect(graph.Undo()).Should(BeTrue())
+			Expect(graph.vertices).Should(HaveLen(1))
+			Expect(graph.egress).Should(HaveLen(1))
+			Expect(graph.egress["S"]).Should(BeEmpty())
+
+			Expect(graph.Redo()).Should(BeTrue())
+			Expect(graph.vertices).Should(HaveLen(3))
+			Expect(weightOf("S", "A")).Should(BeEquivalentTo(1))
+			Expect(weightOf("A", "T")).Should(BeEquivalentTo(2))
+			Expect(graph.egress["A"]["X"].weight).Should(BeEquivalentTo(1))
+			Expect(graph.ingress["X"]["A"]).Should(BeIdenticalTo(graph.egress["A"]["X"]))
+		})
+
+		It("Given a graph with the journal, when make a change after undo, then the entries undone are discarded.", func() {
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.Undo()
+			graph.AddVertex("A", nil)
+			Expect(graph.Redo()).Should(BeFalse())
+			Expect(graph.vertices).Should(HaveLen(2))
+			Expect(graph.vertices).ShouldNot(HaveKey("T"))
+		})
+
+		It("Given a graph with the journal, when restore the checkpoints, then the graph goes back and forth to them.", func() {
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.Checkpoint("vertices")
+			graph.AddEdge("S", "T", 1, nil)
+			graph.UpdateEdgeWeight("S", "T", 5)
+			graph.Checkpoint("edges")
+
+			Expect(graph.RestoreCheckpoint("vertices")).ShouldNot(HaveOccurred())
+			Expect(weightOf("S", "T")).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(graph.vertices).Should(HaveLen(2))
+			Expect(graph.RestoreCheckpoint("edges")).ShouldNot(HaveOccurred())
+			Expect(weightOf("S", "T")).Should(BeEquivalentTo(5))
+			Expect(graph.RestoreCheckpoint("vertices")).ShouldNot(HaveOccurred())
+			graph.Checkpoint("edges")
+			Expect(graph.RestoreCheckpoint("edges")).ShouldNot(HaveOccurred())
+			Expect(weightOf("S", "T")).Should(BeEquivalentTo(math.Inf(1)))
+		})
+
+		It("Given a graph, when change, undo and redo it, then the version always increases.", func() {
+			Expect(graph.Version()).Should(BeZero())
+			graph.AddVertex("S", nil)
+			Expect(graph.Version()).Should(BeEquivalentTo(1))
+			graph.Batch(func(tx *Tx) error {
+				tx.AddVertex("T", nil)
+				return tx.AddEdge("S", "T", 1, nil)
+			})
+			Expect(graph.Version()).Should(BeEquivalentTo(2))
+			frozen := graph.Freeze()
+			graph.Undo()
+			Expect(graph.Version()).Should(BeEquivalentTo(3))
+			graph.Redo()
+			Expect(graph.Version()).Should(BeEquivalentTo(4))
+			Expect(frozen.Version()).Should(BeEquivalentTo(2))
+		})
+
+		It("Given a graph with a shortest path tree, when undo and redo, then the tree is kept up to date.", func() {
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "A", 1, nil)
+			graph.AddEdge("A", "T", 1, nil)
+			graph.AddEdge("S", "T", 5, nil)
+			tree, err := graph.ShortestPathTree("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			graph.DeleteVertex("A")
+			graph.Undo()
+			dist, _, err := tree.Distances()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "A": 1, "T": 2}))
+			graph.Redo()
+			dist, _, err = tree.Distances()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(map[ID]float64{"S": 0, "T": 5}))
+		})
+	})
+})
@@ -3,38 +3,57 @@
 package goraph
 
 import (
+	"context"
 	"math"
 )
 
 // Kisp gets top k shortest independent path between two vertex in the graph.
 // Independent means no vertex is shared between path.
 func (graph *Graph) Kisp(source, destination ID, topK int) ([]float64, [][]ID, error) {
+	return graph.KispCtx(context.Background(), source, destination, topK)
+}
+
+// KispCtx gets top k shortest independent path between two vertex in the graph, and stops when the context is done.
+// If the context is done, the paths found so far are returned together with the error of the context.
+func (graph *Graph) KispCtx(ctx context.Context, source, destination ID, topK int) ([]float64, [][]ID, error) {
 	var err error
-	var i, k int
-	var dijkstraDist map[ID]float64
-	var dijkstraPrev map[ID]ID
+	var dijkstraDist []float64
+	var dijkstraPrev []int32
+	compact := graph.compact()
+	target, err := compact.vertexIndex(destination)
+	if err != nil {
+		return nil, nil, err
+	}
+	start, err := compact.vertexIndex(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	distTopK := make([]float64, topK)
 	pathTopK := make([][]ID, topK)
 	for i := 0; i < topK; i++ {
 		distTopK[i] = math.Inf(1)
 	}
 
-	dijkstraDist, dijkstraPrev, err = graph.Dijkstra(source)
+	dijkstraDist, dijkstraPrev, err = compact.dijkstra(ctx, start, target, nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
-	distTopK[0] = dijkstraDist[destination]
-	pathTopK[0] = getPath(dijkstraPrev, destination)
+	distTopK[0] = dijkstraDist[target]
+	pathTopK[0] = compact.getPath(dijkstraPrev, target)
 
-	for k = 1; k < topK && distTopK[k-1] != math.Inf(1); k++ {
-		for i = 0; i < len(pathTopK[k-1])-1; i++ {
-			graph.DisableEdge(pathTopK[k-1][i], pathTopK[k-1][i+1])
+	blocked := newBlocking()
+	for k := 1; k < topK && distTopK[k-1] != math.Inf(1); k++ {
+		for i := 0; i < len(pathTopK[k-1])-1; i++ {
+			blocked.blockEdge(pathTopK[k-1][i], pathTopK[k-1][i+1])
+		}
+		dijkstraDist, dijkstraPrev, err = compact.dijkstra(ctx, start, target, compact.block(blocked), nil)
+		if err != nil {
+			break
 		}
-		dijkstraDist, dijkstraPrev, _ = graph.Dijkstra(source)
-		distTopK[k] = dijkstraDist[destination]
-		pathTopK[k] = getPath(dijkstraPrev, destination)
+		distTopK[k] = dijkstraDist[target]
+		pathTopK[k] = compact.getPath(dijkstraPrev, target)
 	}
-	graph.Reset()
 
-	return distTopK, pathTopK, nil
+	return distTopK, pathTopK, err
 }
@@ -3,6 +3,7 @@
 package goraph
 
 import (
+	"context"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"math"
@@ -36,6 +37,18 @@ var _ = Describe("Tests of Kisp", func() {
 			Expect(path).Should(BeNil())
 		})
 
+		It("Given a context done during the calculation, when call kisp api with the context, then get the paths found so far and the error of the context.", func() {
+			dist, path, err := graph.KispCtx(&countdownContext{context.Background(), 1}, "C", "H", 3)
+			Expect(err).Should(Equal(context.Canceled))
+			Expect(dist).Should(BeEquivalentTo([]float64{5, math.Inf(1), math.Inf(1)}))
+			Expect(path).Should(BeEquivalentTo([][]ID{{"C", "E", "F", "H"}, nil, nil}))
+			for _, out := range graph.egress {
+				for _, edge := range out {
+					Expect(edge.enable).Should(BeTrue())
+				}
+			}
+		})
+
 		It("Given a graph with negative edge, when call kisp api, then get error.", func() {
 			graph.AddEdge("F", "E", -1, nil)
 			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
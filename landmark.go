@@ -0,0 +1,317 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// LandmarkStrategy is the way to select the landmarks.
+type LandmarkStrategy int
+
+const (
+	// RandomLandmarks selects the landmarks randomly.
+	RandomLandmarks LandmarkStrategy = iota
+	// FarthestLandmarks selects each landmark as far as possible from the ones selected before.
+	FarthestLandmarks
+	// AvoidLandmarks selects each landmark in the region of a shortest path tree where the lower bounds of the ones selected before are the worst.
+	AvoidLandmarks
+)
+
+// Landmarks is the preprocessed shortest distances from and to a few landmarks,
+// which give the lower bounds of the distances between any two vertices by the triangle inequality.
+// It answers point-to-point shortest path queries by A* search guided by the lower bounds, known as ALT.
+// Queries can run concurrently, and run on the graph as it was when the landmarks were built.
+// http://www.cs.princeton.edu/courses/archive/spr06/cos423/Handouts/GW05.pdf
+type Landmarks struct {
+	compact   *compactGraph
+	landmarks []int32
+	// from is the distances from each landmark to all the vertices, and to is the ones from all the vertices to each landmark.
+	from [][]float64
+	to   [][]float64
+}
+
+// BuildLandmarks selects n landmarks by the strategy and calculates their distances by Dijkstra along the egress and ingress edges.
+// All the vertices are selected if there are no more than n vertices.
+// The random choices are seeded with a constant in the deterministic mode.
+// Try to build the landmarks of a graph with negative edges will get ErrNegativeWeight.
+func (graph *Graph) BuildLandmarks(n int, strategy LandmarkStrategy) (*Landmarks, error) {
+	compact := graph.compact()
+	if compact.err != nil {
+		return nil, compact.err
+	}
+
+	seed := time.Now().UnixNano()
+	if graph.deterministic {
+		seed = 1
+	}
+	random := rand.New(rand.NewSource(seed))
+	if n > len(compact.ids) {
+		n = len(compact.ids)
+	}
+
+	landmarks := &Landmarks{compact: compact}
+	reversed := compact.reverse()
+	for len(landmarks.landmarks) < n {
+		var next int32
+		switch strategy {
+		case FarthestLandmarks:
+			next = landmarks.farthest(random)
+		case AvoidLandmarks:
+			next = landmarks.avoid(random)
+		default:
+			next = landmarks.random(random)
+		}
+
+		from, _, _ := compact.dijkstra(context.Background(), next, -1, nil, nil)
+		to, _, _ := reversed.dijkstra(context.Background(), next, -1, nil, nil)
+		landmarks.landmarks = append(landmarks.landmarks, next)
+		landmarks.from = append(landmarks.from, from)
+		landmarks.to = append(landmarks.to, to)
+	}
+
+	return landmarks, nil
+}
+
+// Vertices gets the landmarks.
+func (landmarks *Landmarks) Vertices() []ID {
+	ids := make([]ID, len(landmarks.landmarks))
+	for i, each := range landmarks.landmarks {
+		ids[i] = landmarks.compact.ids[each]
+	}
+
+	return ids
+}
+
+// LowerBound gets the lower bound of the distance between two vertices, +Inf if the destination is known to be unreachable.
+// Try to get the lower bound of a vertex not in the landmarks will get an error.
+func (landmarks *Landmarks) LowerBound(source, destination ID) (float64, error) {
+	s, err := landmarks.compact.vertexIndex(source)
+	if err != nil {
+		return 0, err
+	}
+	t, err := landmarks.compact.vertexIndex(destination)
+	if err != nil {
+		return 0, err
+	}
+
+	return landmarks.lowerBound(s, t), nil
+}
+
+// ShortestPath gets the shortest path between two vertices by A* search with the lower bounds of the landmarks.
+// It gets +Inf and a nil path if the destination is unreachable.
+// Try to query a vertex not in the landmarks will get an error.
+func (landmarks *Landmarks) ShortestPath(source, destination ID) (dist float64, path []ID, err error) {
+	compact := landmarks.compact
+	s, err := compact.vertexIndex(source)
+	if err != nil {
+		return math.Inf(1), nil, err
+	}
+	t, err := compact.vertexIndex(destination)
+	if err != nil {
+		return math.Inf(1), nil, err
+	}
+
+	distances := map[int32]float64{s: 0}
+	previous := map[int32]int32{s: -1}
+	bounds := make(map[int32]float64)
+	settled := make(map[int32]bool)
+	bound := func(v int32) float64 {
+		if h, exists := bounds[v]; exists {
+			return h
+		}
+		bounds[v] = landmarks.lowerBound(v, t)
+		return bounds[v]
+	}
+
+	queue := NewBinaryHeap(0)
+	queue.Push(int(s), bound(s))
+	for queue.Len() != 0 {
+		vertex, key := queue.Pop()
+		min := int32(vertex)
+		if settled[min] || key > distances[min]+bound(min) {
+			continue
+		}
+		if min == t {
+			break
+		}
+		settled[min] = true
+		for e := compact.offsets[min]; e < compact.offsets[min+1]; e++ {
+			to := compact.targets[e]
+			weight := distances[min] + compact.weights[e]
+			if d, exists := distances[to]; exists && weight >= d {
+				continue
+			}
+			if h := bound(to); !math.IsInf(h, 1) {
+				distances[to] = weight
+				previous[to] = min
+				queue.Push(int(to), weight+h)
+			}
+		}
+	}
+
+	dist, exists := distances[t]
+	if !exists {
+		return math.Inf(1), nil, nil
+	}
+	for each := t; each >= 0; each = previous[each] {
+		path = append(path, compact.ids[each])
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return dist, path, nil
+}
+
+// lowerBound gets the best lower bound of the distance from s to t among the landmarks by the triangle inequality:
+// d(s, t) >= d(l, t) - d(l, s) and d(s, t) >= d(s, l) - d(t, l).
+func (landmarks *Landmarks) lowerBound(s, t int32) float64 {
+	best := 0.0
+	for i := range landmarks.landmarks {
+		from, to := landmarks.from[i], landmarks.to[i]
+		if !math.IsInf(from[s], 1) || !math.IsInf(from[t], 1) {
+			best = math.Max(best, from[t]-from[s])
+		}
+		if !math.IsInf(to[s], 1) || !math.IsInf(to[t], 1) {
+			best = math.Max(best, to[s]-to[t])
+		}
+	}
+
+	return best
+}
+
+func (landmarks *Landmarks) random(random *rand.Rand) int32 {
+	for {
+		next := int32(random.Intn(len(landmarks.compact.ids)))
+		if !landmarks.isLandmark(next) {
+			return next
+		}
+	}
+}
+
+// farthest gets the vertex with the max sum of the distances from and to the nearest landmark, the first landmark is selected randomly.
+// The vertices unreachable from or to all the landmarks are the farthest.
+func (landmarks *Landmarks) farthest(random *rand.Rand) int32 {
+	if len(landmarks.landmarks) == 0 {
+		return landmarks.random(random)
+	}
+
+	best, farthest := math.Inf(-1), int32(-1)
+	for v := range landmarks.compact.ids {
+		if landmarks.isLandmark(int32(v)) {
+			continue
+		}
+		nearest := math.Inf(1)
+		for i := range landmarks.landmarks {
+			nearest = math.Min(nearest, landmarks.from[i][v]+landmarks.to[i][v])
+		}
+		if nearest > best {
+			best, farthest = nearest, int32(v)
+		}
+	}
+
+	return farthest
+}
+
+// avoid gets the landmark by the avoid heuristic, the first landmark is selected as the farthest.
+// It grows a shortest path tree from a random root, weighs each vertex by the gap between its distance and lower bound from the root,
+// and walks down from the root along the heaviest subtrees without landmarks to a leaf.
+func (landmarks *Landmarks) avoid(random *rand.Rand) int32 {
+	if len(landmarks.landmarks) == 0 {
+		return landmarks.farthest(random)
+	}
+
+	compact := landmarks.compact
+	root := landmarks.random(random)
+	dist, prev, _ := compact.dijkstra(context.Background(), root, -1, nil, nil)
+	children := make(map[int32][]int32)
+	for v, parent := range prev {
+		if parent >= 0 {
+			children[parent] = append(children[parent], int32(v))
+		}
+	}
+	// the vertices are ordered from the root down the tree, so the subtrees are summed up in the reverse order.
+	order := []int32{root}
+	for i := 0; i < len(order); i++ {
+		order = append(order, children[order[i]]...)
+	}
+
+	size := make([]float64, len(dist))
+	covered := make([]bool, len(dist))
+	for i := len(order) - 1; i >= 0; i-- {
+		v := order[i]
+		if landmarks.isLandmark(v) {
+			covered[v] = true
+		}
+		if covered[v] {
+			size[v] = 0
+		} else {
+			size[v] += dist[v] - landmarks.lowerBound(root, v)
+		}
+		if parent := prev[v]; parent >= 0 {
+			covered[parent] = covered[parent] || covered[v]
+			size[parent] += size[v]
+		}
+	}
+
+	leaf := root
+	for {
+		next := int32(-1)
+		for _, child := range children[leaf] {
+			if size[child] > 0 && (next < 0 || size[child] > size[next]) {
+				next = child
+			}
+		}
+		if next < 0 {
+			break
+		}
+		leaf = next
+	}
+
+	return leaf
+}
+
+func (landmarks *Landmarks) isLandmark(v int32) bool {
+	for _, each := range landmarks.landmarks {
+		if each == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reverse gets the compact graph with all the edges reversed, the vertices are indexed the same.
+func (compact *compactGraph) reverse() *compactGraph {
+	reversed := &compactGraph{
+		ids:           compact.ids,
+		index:         compact.index,
+		offsets:       make([]int32, len(compact.offsets)),
+		targets:       make([]int32, len(compact.targets)),
+		weights:       make([]float64, len(compact.weights)),
+		deterministic: compact.deterministic,
+		err:           compact.err,
+	}
+	for _, target := range compact.targets {
+		reversed.offsets[target+1]++
+	}
+	for i := 1; i < len(reversed.offsets); i++ {
+		reversed.offsets[i] += reversed.offsets[i-1]
+	}
+	next := make([]int32, len(compact.ids))
+	copy(next, reversed.offsets)
+	for from := range compact.ids {
+		for e := compact.offsets[from]; e < compact.offsets[from+1]; e++ {
+			to := compact.targets[e]
+			reversed.targets[next[to]] = int32(from)
+			reversed.weights[next[to]] = compact.weights[e]
+			next[to]++
+		}
+	}
+
+	return reversed
+}
@@ -0,0 +1,159 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var _ = Describe("Tests of Landmarks", func() {
+	var (
+		graph *Graph
+	)
+
+	strategies := map[string]LandmarkStrategy{
+		"random":   RandomLandmarks,
+		"farthest": FarthestLandmarks,
+		"avoid":    AvoidLandmarks,
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "T", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with negative edge, when build landmarks, then get ErrNegativeWeight.", func() {
+			graph.UpdateEdgeWeight("S", "T", -1)
+			landmarks, err := graph.BuildLandmarks(1, RandomLandmarks)
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(landmarks).Should(BeNil())
+		})
+
+		It("Given landmarks, when query with non-existed vertex, then get ErrVertexNotFound.", func() {
+			landmarks, err := graph.BuildLandmarks(1, RandomLandmarks)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dist, path, err := landmarks.ShortestPath("X", "T")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+			_, _, err = landmarks.ShortestPath("S", "X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			_, err = landmarks.LowerBound("S", "X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+		})
+
+		It("Given more landmarks than vertices, when build landmarks, then all the vertices are landmarks.", func() {
+			for name, strategy := range strategies {
+				landmarks, err := graph.BuildLandmarks(5, strategy)
+				Expect(err).ShouldNot(HaveOccurred(), name)
+				Expect(landmarks.Vertices()).Should(ConsistOf("S", "T"), name)
+			}
+		})
+
+		It("Given landmarks, when query an unreachable vertex or the source itself, then get +Inf or 0.", func() {
+			landmarks, _ := graph.BuildLandmarks(2, RandomLandmarks)
+
+			dist, path, err := landmarks.ShortestPath("T", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+			Expect(landmarks.LowerBound("T", "S")).Should(Equal(math.Inf(1)))
+
+			dist, path, err = landmarks.ShortestPath("S", "S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeZero())
+			Expect(path).Should(Equal([]ID{"S"}))
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(5))
+			graph = NewGraph()
+			graph.SetDeterministic(true)
+			for i := 0; i < 150; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 150; i++ {
+				for j := 0; j < 3; j++ {
+					graph.AddEdge(i, random.Intn(150), float64(random.Intn(20)), nil)
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given landmarks by each strategy, when query the shortest paths, then get the same distances as dijkstra with admissible lower bounds.", func() {
+			for name, strategy := range strategies {
+				landmarks, err := graph.BuildLandmarks(4, strategy)
+				Expect(err).ShouldNot(HaveOccurred(), name)
+				Expect(landmarks.Vertices()).Should(HaveLen(4), name)
+
+				for source := 0; source < 150; source += 7 {
+					expectedDist, _, _ := graph.Dijkstra(source)
+					for destination, expected := range expectedDist {
+						bound, err := landmarks.LowerBound(source, destination)
+						Expect(err).ShouldNot(HaveOccurred())
+						Expect(bound).Should(BeNumerically("<=", expected), name)
+
+						dist, path, err := landmarks.ShortestPath(source, destination)
+						Expect(err).ShouldNot(HaveOccurred())
+						if math.IsInf(expected, 1) {
+							Expect(dist).Should(Equal(expected), name)
+							Expect(path).Should(BeNil(), name)
+							continue
+						}
+						Expect(dist).Should(BeNumerically("~", expected, 1e-9), name)
+						Expect(path[0]).Should(Equal(source))
+						Expect(path[len(path)-1]).Should(Equal(destination))
+						Expect(graph.GetPathWeight(path)).Should(BeNumerically("~", expected, 1e-9), name)
+					}
+				}
+			}
+		})
+
+		It("Given the deterministic mode, when build landmarks twice, then get the same landmarks.", func() {
+			for name, strategy := range strategies {
+				first, _ := graph.BuildLandmarks(4, strategy)
+				second, _ := graph.BuildLandmarks(4, strategy)
+				Expect(first.Vertices()).Should(Equal(second.Vertices()), name)
+			}
+		})
+	})
+})
+
+func benchmarkLandmarks(b *testing.B, strategy LandmarkStrategy) {
+	landmarks, _ := newGridGraph(100).BuildLandmarks(16, strategy)
+	random := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		landmarks.ShortestPath(random.Intn(10000), random.Intn(10000))
+	}
+}
+
+func BenchmarkLandmarksRandom(b *testing.B) {
+	benchmarkLandmarks(b, RandomLandmarks)
+}
+
+func BenchmarkLandmarksFarthest(b *testing.B) {
+	benchmarkLandmarks(b, FarthestLandmarks)
+}
+
+func BenchmarkLandmarksAvoid(b *testing.B) {
+	benchmarkLandmarks(b, AvoidLandmarks)
+}
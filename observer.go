@@ -0,0 +1,87 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+// observer is a listener calling back the functions subscribed, the nil ones are skipped.
+type observer struct {
+	onVertexAdded       func(id ID)
+	onVertexDeleted     func(id ID)
+	onEdgeAdded         func(from, to ID, weight float64)
+	onEdgeWeightUpdated func(from, to ID, old, weight float64)
+	onEdgeDeleted       func(from, to ID, weight float64)
+}
+
+// OnVertexAdded subscribes the callback to the vertices added by AddVertex and AddVertexWithEdges, and gets the function to unsubscribe it.
+// The callbacks are called synchronously after each change in the order of subscription, and must not change the graph.
+func (graph *Graph) OnVertexAdded(callback func(id ID)) (unsubscribe func()) {
+	return graph.subscribe(&observer{onVertexAdded: callback})
+}
+
+// OnVertexDeleted subscribes the callback to the vertices deleted by DeleteVertex, and gets the function to unsubscribe it.
+// The edges connected to the vertex are deleted one by one before it, calling the callbacks of OnEdgeDeleted.
+func (graph *Graph) OnVertexDeleted(callback func(id ID)) (unsubscribe func()) {
+	return graph.subscribe(&observer{onVertexDeleted: callback})
+}
+
+// OnEdgeAdded subscribes the callback to the edges added by AddEdge and AddVertexWithEdges, and gets the function to unsubscribe it.
+func (graph *Graph) OnEdgeAdded(callback func(from, to ID, weight float64)) (unsubscribe func()) {
+	return graph.subscribe(&observer{onEdgeAdded: callback})
+}
+
+// OnEdgeWeightUpdated subscribes the callback to the weights updated by UpdateEdgeWeight, and gets the function to unsubscribe it.
+// The callback gets the weights before and after the update. It is also called when AddVertexWithEdges replaces an edge.
+func (graph *Graph) OnEdgeWeightUpdated(callback func(from, to ID, old, weight float64)) (unsubscribe func()) {
+	return graph.subscribe(&observer{onEdgeWeightUpdated: callback})
+}
+
+// OnEdgeDeleted subscribes the callback to the edges deleted by DeleteEdge and DeleteVertex, and gets the function to unsubscribe it.
+func (graph *Graph) OnEdgeDeleted(callback func(from, to ID, weight float64)) (unsubscribe func()) {
+	return graph.subscribe(&observer{onEdgeDeleted: callback})
+}
+
+func (graph *Graph) subscribe(each listener) func() {
+	graph.listeners = append(graph.listeners, each)
+	return func() {
+		graph.unsubscribe(each)
+	}
+}
+
+func (graph *Graph) unsubscribe(each listener) {
+	for i, subscribed := range graph.listeners {
+		if subscribed == each {
+			// the listeners are copied, so a callback unsubscribing does not shift the ones being notified.
+			graph.listeners = append(graph.listeners[:i:i], graph.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+func (observer *observer) vertexAdded(id ID) {
+	if observer.onVertexAdded != nil {
+		observer.onVertexAdded(id)
+	}
+}
+
+func (observer *observer) vertexDeleted(id ID) {
+	if observer.onVertexDeleted != nil {
+		observer.onVertexDeleted(id)
+	}
+}
+
+func (observer *observer) edgeAdded(from, to ID, weight float64) {
+	if observer.onEdgeAdded != nil {
+		observer.onEdgeAdded(from, to, weight)
+	}
+}
+
+func (observer *observer) edgeUpdated(from, to ID, old, weight float64) {
+	if observer.onEdgeWeightUpdated != nil {
+		observer.onEdgeWeightUpdated(from, to, old, weight)
+	}
+}
+
+func (observer *observer) edgeDeleted(from, to ID, weight float64) {
+	if observer.onEdgeDeleted != nil {
+		observer.onEdgeDeleted(from, to, weight)
+	}
+}
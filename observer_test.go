@@ -0,0 +1,146 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tests of observers", func() {
+	var (
+		graph  *Graph
+		events []string
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		events = nil
+		graph.OnVertexAdded(func(id ID) {
+			events = append(events, fmt.Sprint("vertex added ", id))
+		})
+		graph.OnVertexDeleted(func(id ID) {
+			events = append(events, fmt.Sprint("vertex deleted ", id))
+		})
+		graph.OnEdgeAdded(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge added ", from, to, weight))
+		})
+		graph.OnEdgeWeightUpdated(func(from, to ID, old, weight float64) {
+			events = append(events, fmt.Sprint("edge updated ", from, to, old, weight))
+		})
+		graph.OnEdgeDeleted(func(from, to ID, weight float64) {
+			events = append(events, fmt.Sprint("edge deleted ", from, to, weight))
+		})
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	It("Given a graph with observers, when change the graph, then the callbacks are called after each change.", func() {
+		graph.AddVertex("S", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "T", 1, nil)
+		graph.UpdateEdgeWeight("S", "T", 2)
+		graph.DeleteEdge("S", "T")
+		graph.DeleteVertex("T")
+
+		Expect(events).Should(Equal([]string{
+			"vertex added S",
+			"vertex added T",
+			"edge added ST1",
+			"edge updated ST1 2",
+			"edge deleted ST2",
+			"vertex deleted T",
+		}))
+	})
+
+	It("Given a graph with observers, when the changes fail, then the callbacks are not called.", func() {
+		graph.AddVertex("S", nil)
+		events = nil
+
+		graph.AddVertex("S", nil)
+		graph.AddEdge("S", "X", 1, nil)
+		graph.UpdateEdgeWeight("S", "S", 1)
+		graph.DeleteEdge("S", "X")
+		graph.DeleteVertex("X")
+		Expect(events).Should(BeEmpty())
+	})
+
+	It("Given a graph with observers, when add a vertex with edges, then the callbacks are called for the vertex and each edge.", func() {
+		graph.AddVertex("S", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "T", 1, nil)
+		events = nil
+
+		graph.AddVertexWithEdges(&myVertex{"V", map[ID]float64{"T": 3}, map[ID]float64{}})
+		Expect(events).Should(Equal([]string{"vertex added V", "edge added VT3"}))
+
+		events = nil
+		graph.DeleteVertex("V")
+		graph.AddVertexWithEdges(&myVertex{"V", map[ID]float64{}, map[ID]float64{}})
+		Expect(events).Should(Equal([]string{"edge deleted VT3", "vertex deleted V", "vertex added V"}))
+	})
+
+	It("Given observers keeping a copy of the edges, when delete a vertex, then the edges of the vertex are deleted before it and the copy is kept in sync.", func() {
+		edges := make(map[string]float64)
+		graph.OnEdgeAdded(func(from, to ID, weight float64) {
+			edges[fmt.Sprint(from, to)] = weight
+		})
+		graph.OnEdgeDeleted(func(from, to ID, weight float64) {
+			_, err := graph.GetVertex(from)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = graph.GetVertex(to)
+			Expect(err).ShouldNot(HaveOccurred())
+			delete(edges, fmt.Sprint(from, to))
+		})
+		graph.SetDeterministic(true)
+		graph.AddVertex("S", nil)
+		graph.AddVertex("A", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "A", 1, nil)
+		graph.AddEdge("A", "T", 2, nil)
+		graph.AddEdge("S", "T", 3, nil)
+		graph.AddEdge("A", "A", 4, nil)
+		events = nil
+
+		graph.DeleteVertex("A")
+		Expect(events).Should(Equal([]string{
+			"edge deleted AA4",
+			"edge deleted AT2",
+			"edge deleted SA1",
+			"vertex deleted A",
+		}))
+		Expect(edges).Should(Equal(map[string]float64{"ST": 3}))
+	})
+
+	It("Given an observer, when unsubscribe it, then it is not called any more.", func() {
+		var added []ID
+		unsubscribe := graph.OnVertexAdded(func(id ID) {
+			added = append(added, id)
+		})
+		graph.AddVertex("S", nil)
+		unsubscribe()
+		graph.AddVertex("T", nil)
+		unsubscribe()
+
+		Expect(added).Should(Equal([]ID{"S"}))
+		Expect(events).Should(Equal([]string{"vertex added S", "vertex added T"}))
+	})
+
+	It("Given an observer unsubscribing itself, when it is called, then the other observers are still called.", func() {
+		var unsubscribe func()
+		unsubscribe = graph.OnVertexAdded(func(id ID) {
+			unsubscribe()
+		})
+		var added []ID
+		graph.OnVertexAdded(func(id ID) {
+			added = append(added, id)
+		})
+
+		graph.AddVertex("S", nil)
+		graph.AddVertex("T", nil)
+		Expect(added).Should(Equal([]ID{"S", "T"}))
+	})
+})
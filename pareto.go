@@ -0,0 +1,138 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"sort"
+)
+
+type label struct {
+	vertex    ID
+	costs     []float64
+	prev      *label
+	dominated bool
+}
+
+// Pareto gets all the non-dominated paths between two vertex in the graph under multiple criteria.
+// Each criterion gets a non-negative cost of an edge, a nil criterion means the weight of the edge.
+// A path is dominated if another path costs no more under all criteria.
+// At most maxLabels labels are kept for each vertex to bound the memory, the lexicographically largest ones are dropped.
+// No limit is applied if maxLabels is not positive, while a positive limit may lose some non-dominated paths.
+// The paths are returned in lexicographic order of their costs.
+// https://en.wikipedia.org/wiki/Multi-objective_optimization
+func (graph *Graph) Pareto(source, destination ID, criteria []EdgeMetric, maxLabels int) (costs [][]float64, paths [][]ID, err error) {
+	if _, exists := graph.vertices[source]; !exists {
+		return nil, nil, &VertexError{ErrVertexNotFound, source}
+	}
+
+	if _, exists := graph.vertices[destination]; !exists {
+		return nil, nil, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	labels := make(map[ID][]*label)
+	start := &label{source, make([]float64, len(criteria)), nil, false}
+	labels[source] = []*label{start}
+	queue := []*label{start}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.dominated {
+			continue
+		}
+
+		for _, to := range graph.egressIDs(current.vertex) {
+			edge := graph.egress[current.vertex][to]
+			next := &label{to, make([]float64, len(criteria)), current, false}
+			for i, criterion := range criteria {
+				cost := edge.getWeight()
+				if criterion != nil {
+					cost = criterion(current.vertex, to)
+				}
+				if cost < 0 {
+					return nil, nil, &EdgeError{ErrNegativeWeight, current.vertex, to}
+				}
+				next.costs[i] = current.costs[i] + cost
+			}
+			if _, exists := graph.vertices[to]; !exists || !edge.enable {
+				continue
+			}
+
+			if merged, ok := mergeLabel(labels[to], next, maxLabels); ok {
+				labels[to] = merged
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, each := range labels[destination] {
+		costs = append(costs, each.costs)
+		paths = append(paths, getLabelPath(each))
+	}
+
+	return costs, paths, nil
+}
+
+// mergeLabel adds the label into the non-dominated labels of a vertex.
+// It gets false if the label is dominated by any existing one or dropped due to the limit.
+func mergeLabel(labels []*label, next *label, maxLabels int) ([]*label, bool) {
+	merged := make([]*label, 0, len(labels)+1)
+	for _, each := range labels {
+		if dominates(each.costs, next.costs) {
+			return labels, false
+		}
+	}
+	for _, each := range labels {
+		if dominates(next.costs, each.costs) {
+			each.dominated = true
+		} else {
+			merged = append(merged, each)
+		}
+	}
+	merged = append(merged, next)
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return lessCosts(merged[i].costs, merged[j].costs)
+	})
+	if maxLabels > 0 && len(merged) > maxLabels {
+		for _, each := range merged[maxLabels:] {
+			each.dominated = true
+		}
+		merged = merged[:maxLabels]
+	}
+
+	return merged, !next.dominated
+}
+
+// dominates reports whether costs a is no more than costs b under all criteria.
+func dominates(a, b []float64) bool {
+	for i := range a {
+		if a[i] > b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lessCosts(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return false
+}
+
+func getLabelPath(last *label) (path []ID) {
+	for each := last; each != nil; each = each.prev {
+		path = append(path, each.vertex)
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return
+}
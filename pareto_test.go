@@ -0,0 +1,112 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tests of Pareto", func() {
+	var (
+		graph *Graph
+		money map[ID]map[ID]float64
+		price EdgeMetric
+	)
+
+	BeforeEach(func() {
+		graph = NewGraph()
+		graph.AddVertex("S", nil)
+		graph.AddVertex("A", nil)
+		graph.AddVertex("B", nil)
+		graph.AddVertex("C", nil)
+		graph.AddVertex("T", nil)
+		graph.AddEdge("S", "A", 1, nil)
+		graph.AddEdge("A", "T", 1, nil)
+		graph.AddEdge("S", "B", 5, nil)
+		graph.AddEdge("B", "T", 5, nil)
+		graph.AddEdge("S", "C", 3, nil)
+		graph.AddEdge("C", "T", 3, nil)
+		graph.AddEdge("A", "C", 1, nil)
+		graph.AddEdge("S", "T", 20, nil)
+		graph.AddEdge("T", "S", 1, nil)
+		money = map[ID]map[ID]float64{
+			"S": {"A": 10, "B": 1, "C": 5, "T": 30},
+			"A": {"T": 10, "C": 0},
+			"B": {"T": 1},
+			"C": {"T": 5},
+			"T": {"S": 0},
+		}
+		price = func(from, to ID) float64 {
+			return money[from][to]
+		}
+		Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		graph = nil
+	})
+
+	Context("exception test", func() {
+		It("Given a graph without vertex X, when call pareto api with X, then get two nil and error", func() {
+			costs, paths, err := graph.Pareto("X", "T", []EdgeMetric{nil, price}, 0)
+			Expect(costs).Should(BeNil())
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+
+			costs, paths, err = graph.Pareto("S", "X", []EdgeMetric{nil, price}, 0)
+			Expect(costs).Should(BeNil())
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph with negative cost, when call pareto api, then get two nil and error", func() {
+			money["C"]["T"] = -1
+			costs, paths, err := graph.Pareto("S", "T", []EdgeMetric{nil, price}, 0)
+			Expect(costs).Should(BeNil())
+			Expect(paths).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		It("Given a graph with two costs, when call pareto api, then get all the non-dominated paths in lexicographic order.", func() {
+			costs, paths, err := graph.Pareto("S", "T", []EdgeMetric{nil, price}, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(costs).Should(BeEquivalentTo([][]float64{{2, 20}, {5, 15}, {6, 10}, {10, 2}}))
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"S", "A", "T"}, {"S", "A", "C", "T"}, {"S", "C", "T"}, {"S", "B", "T"}}))
+		})
+
+		It("Given a graph with three costs, when call pareto api, then a path better in the third cost is kept.", func() {
+			hop := func(from, to ID) float64 {
+				return 1
+			}
+			costs, paths, err := graph.Pareto("S", "T", []EdgeMetric{nil, price, hop}, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(costs).Should(BeEquivalentTo([][]float64{{2, 20, 2}, {5, 15, 3}, {6, 10, 2}, {10, 2, 2}, {20, 30, 1}}))
+			Expect(paths[4]).Should(BeEquivalentTo([]ID{"S", "T"}))
+		})
+
+		It("Given a graph with some edges disabled, when call pareto api, then the disabled edges will not be calculated.", func() {
+			graph.DisableEdge("A", "C")
+			costs, paths, err := graph.Pareto("S", "T", []EdgeMetric{nil, price}, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(costs).Should(BeEquivalentTo([][]float64{{2, 20}, {6, 10}, {10, 2}}))
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"S", "A", "T"}, {"S", "C", "T"}, {"S", "B", "T"}}))
+		})
+
+		It("Given a limit of labels, when call pareto api, then at most the limited labels are kept for each vertex.", func() {
+			costs, paths, err := graph.Pareto("S", "T", []EdgeMetric{nil, price}, 1)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(costs).Should(BeEquivalentTo([][]float64{{2, 20}}))
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"S", "A", "T"}}))
+		})
+
+		It("Given the same source and destination, when call pareto api, then get the empty path with zero costs.", func() {
+			costs, paths, err := graph.Pareto("S", "S", []EdgeMetric{nil, price}, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(costs).Should(BeEquivalentTo([][]float64{{0, 0}}))
+			Expect(paths).Should(BeEquivalentTo([][]ID{{"S"}}))
+		})
+	})
+})
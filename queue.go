@@ -0,0 +1,368 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"container/heap"
+	"github.com/starwander/GoFibonacciHeap"
+	"math"
+	"math/bits"
+)
+
+// weightQueue is the priority queue of vertices used by the Dijkstra-style algorithms.
+// The vertex with the best weight under the algebra is extracted first.
+type weightQueue interface {
+	insert(id ID, weight PathWeight)
+	extractMin() ID
+	decreaseKey(id ID, weight PathWeight)
+	num() int
+}
+
+// newWeightQueue gets a fibonacci heap if the weights of the algebra can be ordered by a float64 key.
+// Otherwise a binary heap ordered by the algebra is returned.
+func newWeightQueue(algebra PathAlgebra) weightQueue {
+	if scalar, ok := algebra.(scalarAlgebra); ok {
+		return &fibQueue{fibHeap.NewFibHeap(), scalar}
+	}
+
+	return &algebraQueue{algebra: algebra, index: make(map[ID]int)}
+}
+
+type fibQueue struct {
+	heap    *fibHeap.FibHeap
+	algebra scalarAlgebra
+}
+
+func (queue *fibQueue) insert(id ID, weight PathWeight) {
+	queue.heap.Insert(id, queue.algebra.key(weight))
+}
+
+func (queue *fibQueue) extractMin() ID {
+	min, _ := queue.heap.ExtractMin()
+	return min
+}
+
+func (queue *fibQueue) decreaseKey(id ID, weight PathWeight) {
+	queue.heap.DecreaseKey(id, queue.algebra.key(weight))
+}
+
+func (queue *fibQueue) num() int {
+	return int(queue.heap.Num())
+}
+
+type algebraItem struct {
+	id     ID
+	weight PathWeight
+}
+
+type algebraQueue struct {
+	algebra PathAlgebra
+	items   []*algebraItem
+	index   map[ID]int
+}
+
+func (queue *algebraQueue) insert(id ID, weight PathWeight) {
+	heap.Push(queue, &algebraItem{id, weight})
+}
+
+func (queue *algebraQueue) extractMin() ID {
+	return heap.Pop(queue).(*algebraItem).id
+}
+
+func (queue *algebraQueue) decreaseKey(id ID, weight PathWeight) {
+	if i, exists := queue.index[id]; exists {
+		queue.items[i].weight = weight
+		heap.Fix(queue, i)
+	}
+}
+
+func (queue *algebraQueue) num() int {
+	return len(queue.items)
+}
+
+func (queue *algebraQueue) Len() int {
+	return len(queue.items)
+}
+
+func (queue *algebraQueue) Less(i, j int) bool {
+	return queue.algebra.Better(queue.items[i].weight, queue.items[j].weight)
+}
+
+func (queue *algebraQueue) Swap(i, j int) {
+	queue.items[i], queue.items[j] = queue.items[j], queue.items[i]
+	queue.index[queue.items[i].id] = i
+	queue.index[queue.items[j].id] = j
+}
+
+func (queue *algebraQueue) Push(x interface{}) {
+	item := x.(*algebraItem)
+	queue.index[item.id] = len(queue.items)
+	queue.items = append(queue.items, item)
+}
+
+func (queue *algebraQueue) Pop() interface{} {
+	item := queue.items[len(queue.items)-1]
+	queue.items = queue.items[:len(queue.items)-1]
+	delete(queue.index, item.id)
+	return item
+}
+
+// PriorityQueue is the priority queue of vertices used by the shortest path algorithms, in which the vertices are indexed by int.
+// An implementation may keep the old entries of a vertex when its key is decreased,
+// the algorithms skip the vertices already extracted.
+type PriorityQueue interface {
+	// Push inserts the vertex with the key, or decreases the key of the vertex if it is in the queue.
+	Push(vertex int, key float64)
+	// Pop removes the vertex with the minimum key and gets it together with its key.
+	Pop() (vertex int, key float64)
+	// Len gets the number of entries in the queue.
+	Len() int
+}
+
+// QueueFactory creates an empty priority queue for the vertices indexed from 0 to size-1.
+type QueueFactory func(size int) PriorityQueue
+
+type queueEntry struct {
+	vertex int
+	key    float64
+}
+
+// NewBinaryHeap creates a binary heap with lazy deletion, a decreased key is pushed as a new entry.
+// Entries with equal keys are popped in the order of their vertices. It is the default queue.
+func NewBinaryHeap(size int) PriorityQueue {
+	return &binaryHeap{}
+}
+
+type binaryHeap struct {
+	entries []queueEntry
+}
+
+func (queue *binaryHeap) Push(vertex int, key float64) {
+	queue.entries = append(queue.entries, queueEntry{vertex, key})
+	for i := len(queue.entries) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !queue.less(i, parent) {
+			break
+		}
+		queue.entries[i], queue.entries[parent] = queue.entries[parent], queue.entries[i]
+		i = parent
+	}
+}
+
+func (queue *binaryHeap) Pop() (int, float64) {
+	min := queue.entries[0]
+	last := len(queue.entries) - 1
+	queue.entries[0] = queue.entries[last]
+	queue.entries = queue.entries[:last]
+	for i := 0; ; {
+		smallest := i
+		if left := 2*i + 1; left < last && queue.less(left, smallest) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < last && queue.less(right, smallest) {
+			smallest = right
+		}
+		if smallest == i {
+			break
+		}
+		queue.entries[i], queue.entries[smallest] = queue.entries[smallest], queue.entries[i]
+		i = smallest
+	}
+
+	return min.vertex, min.key
+}
+
+func (queue *binaryHeap) Len() int {
+	return len(queue.entries)
+}
+
+func (queue *binaryHeap) less(i, j int) bool {
+	if queue.entries[i].key != queue.entries[j].key {
+		return queue.entries[i].key < queue.entries[j].key
+	}
+
+	return queue.entries[i].vertex < queue.entries[j].vertex
+}
+
+// NewPairingHeap creates a pairing heap, which decreases the key of a vertex in place.
+// https://en.wikipedia.org/wiki/Pairing_heap
+func NewPairingHeap(size int) PriorityQueue {
+	return &pairingHeap{nodes: make([]*pairingNode, size)}
+}
+
+type pairingNode struct {
+	vertex  int
+	key     float64
+	child   *pairingNode
+	sibling *pairingNode
+	// prev is the parent of the first child, or the left sibling of the others.
+	prev *pairingNode
+}
+
+type pairingHeap struct {
+	root  *pairingNode
+	nodes []*pairingNode
+	size  int
+}
+
+func (queue *pairingHeap) Push(vertex int, key float64) {
+	node := queue.nodes[vertex]
+	if node == nil {
+		node = &pairingNode{vertex: vertex, key: key}
+		queue.nodes[vertex] = node
+		queue.root = meldPairing(queue.root, node)
+		queue.size++
+		return
+	}
+	if key >= node.key {
+		return
+	}
+
+	node.key = key
+	if node == queue.root {
+		return
+	}
+	if node.prev.child == node {
+		node.prev.child = node.sibling
+	} else {
+		node.prev.sibling = node.sibling
+	}
+	if node.sibling != nil {
+		node.sibling.prev = node.prev
+	}
+	node.prev, node.sibling = nil, nil
+	queue.root = meldPairing(queue.root, node)
+}
+
+func (queue *pairingHeap) Pop() (int, float64) {
+	min := queue.root
+	queue.nodes[min.vertex] = nil
+	queue.size--
+
+	var pairs []*pairingNode
+	for child := min.child; child != nil; {
+		first, second := child, child.sibling
+		if second == nil {
+			child = nil
+		} else {
+			child = second.sibling
+			second.prev, second.sibling = nil, nil
+		}
+		first.prev, first.sibling = nil, nil
+		pairs = append(pairs, meldPairing(first, second))
+	}
+	queue.root = nil
+	for i := len(pairs) - 1; i >= 0; i-- {
+		queue.root = meldPairing(queue.root, pairs[i])
+	}
+
+	return min.vertex, min.key
+}
+
+func (queue *pairingHeap) Len() int {
+	return queue.size
+}
+
+func meldPairing(a, b *pairingNode) *pairingNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if b.key < a.key || (b.key == a.key && b.vertex < a.vertex) {
+		a, b = b, a
+	}
+
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+
+	return a
+}
+
+// NewRadixHeap creates a radix heap with lazy deletion, which requires the popped keys to be nondecreasing as in Dijkstra.
+// The keys are bucketed by the highest bit differing from the last popped key, it is the most efficient for integer weights.
+// https://en.wikipedia.org/wiki/Radix_heap
+func NewRadixHeap(size int) PriorityQueue {
+	return &radixHeap{}
+}
+
+type radixHeap struct {
+	buckets [65][]queueEntry
+	last    uint64
+	size    int
+}
+
+func (queue *radixHeap) Push(vertex int, key float64) {
+	bucket := bits.Len64(math.Float64bits(key) ^ queue.last)
+	queue.buckets[bucket] = append(queue.buckets[bucket], queueEntry{vertex, key})
+	queue.size++
+}
+
+func (queue *radixHeap) Pop() (int, float64) {
+	if len(queue.buckets[0]) == 0 {
+		i := 1
+		for len(queue.buckets[i]) == 0 {
+			i++
+		}
+		entries := queue.buckets[i]
+		queue.buckets[i] = nil
+		queue.last = math.Float64bits(entries[0].key)
+		for _, entry := range entries {
+			if key := math.Float64bits(entry.key); key < queue.last {
+				queue.last = key
+			}
+		}
+		for _, entry := range entries {
+			bucket := bits.Len64(math.Float64bits(entry.key) ^ queue.last)
+			queue.buckets[bucket] = append(queue.buckets[bucket], entry)
+		}
+	}
+
+	last := len(queue.buckets[0]) - 1
+	min := queue.buckets[0][last]
+	queue.buckets[0] = queue.buckets[0][:last]
+	queue.size--
+
+	return min.vertex, min.key
+}
+
+func (queue *radixHeap) Len() int {
+	return queue.size
+}
+
+// NewFibonacciHeap creates a fibonacci heap, which decreases the key of a vertex in place.
+// https://en.wikipedia.org/wiki/Fibonacci_heap
+func NewFibonacciHeap(size int) PriorityQueue {
+	return &fibonacciHeap{fibHeap.NewFibHeap(), make([]bool, size)}
+}
+
+type fibonacciHeap struct {
+	heap   *fibHeap.FibHeap
+	queued []bool
+}
+
+func (queue *fibonacciHeap) Push(vertex int, key float64) {
+	if queue.queued[vertex] {
+		queue.heap.DecreaseKey(vertex, key)
+		return
+	}
+
+	queue.queued[vertex] = true
+	queue.heap.Insert(vertex, key)
+}
+
+func (queue *fibonacciHeap) Pop() (int, float64) {
+	vertex, key := queue.heap.ExtractMin()
+	queue.queued[vertex.(int)] = false
+
+	return vertex.(int), key
+}
+
+func (queue *fibonacciHeap) Len() int {
+	return int(queue.heap.Num())
+}
@@ -0,0 +1,95 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"testing"
+)
+
+var queues = map[string]QueueFactory{
+	"binary":    NewBinaryHeap,
+	"pairing":   NewPairingHeap,
+	"radix":     NewRadixHeap,
+	"fibonacci": NewFibonacciHeap,
+}
+
+var _ = Describe("Tests of priority queues", func() {
+	It("Given a priority queue, when push and decrease keys monotonically as dijkstra does, then pop each vertex with its minimum key in nondecreasing order.", func() {
+		for name, newQueue := range queues {
+			random := rand.New(rand.NewSource(1))
+			queue := newQueue(100)
+			keys := make(map[int]float64)
+			var last float64
+			for i := 0; i < 1000; i++ {
+				vertex := random.Intn(100)
+				key := last + float64(random.Intn(50))
+				if old, exists := keys[vertex]; !exists || key < old {
+					keys[vertex] = key
+					queue.Push(vertex, key)
+				}
+				if random.Intn(3) == 0 && queue.Len() != 0 {
+					vertex, key := queue.Pop()
+					Expect(key).Should(BeNumerically(">=", last), name)
+					if key == keys[vertex] {
+						delete(keys, vertex)
+					}
+					last = key
+				}
+			}
+			for queue.Len() != 0 {
+				vertex, key := queue.Pop()
+				Expect(key).Should(BeNumerically(">=", last), name)
+				if key == keys[vertex] {
+					delete(keys, vertex)
+				}
+				last = key
+			}
+			Expect(keys).Should(BeEmpty(), name)
+		}
+	})
+
+	It("Given a graph, when call dijkstra api with each priority queue, then get the same distances.", func() {
+		graph := newBenchmarkGraph(1000, 5)
+		expectedDist, _, err := graph.Dijkstra(0)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		for name, newQueue := range queues {
+			dist, prev, err := graph.DijkstraQueue(0, newQueue)
+			Expect(err).ShouldNot(HaveOccurred(), name)
+			Expect(dist).Should(Equal(expectedDist), name)
+			for to, from := range prev {
+				if from != nil {
+					Expect(dist[from]+graph.egress[from][to].weight).Should(Equal(dist[to]), fmt.Sprint(name, to))
+				}
+			}
+		}
+	})
+})
+
+func benchmarkDijkstraQueue(b *testing.B, queue QueueFactory) {
+	graph := newBenchmarkGraph(10000, 5).Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.DijkstraQueue(0, queue)
+	}
+}
+
+func BenchmarkDijkstraBinaryHeap(b *testing.B) {
+	benchmarkDijkstraQueue(b, NewBinaryHeap)
+}
+
+func BenchmarkDijkstraPairingHeap(b *testing.B) {
+	benchmarkDijkstraQueue(b, NewPairingHeap)
+}
+
+func BenchmarkDijkstraRadixHeap(b *testing.B) {
+	benchmarkDijkstraQueue(b, NewRadixHeap)
+}
+
+func BenchmarkDijkstraFibonacciHeap(b *testing.B) {
+	benchmarkDijkstraQueue(b, NewFibonacciHeap)
+}
@@ -0,0 +1,290 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"container/heap"
+	"math"
+)
+
+// ShortestPathTree is the shortest paths from a source to all other vertices, kept up to date with the changes of the graph.
+// Only the vertices whose distances are affected by a change are calculated again, known as the Ramalingam-Reps algorithm:
+// a shorter edge relaxes the vertices from its head, and a longer or deleted edge in the tree recalculates the subtree under it.
+// Among the predecessors with equal distances, the one chosen may differ from the one chosen by Dijkstra.
+// Disabling and enabling edges are not tracked, Rebuild must be called after them.
+// https://doi.org/10.1006/jagm.1996.0046
+type ShortestPathTree struct {
+	graph    *Graph
+	source   ID
+	dist     map[ID]float64
+	prev     map[ID]ID
+	children map[ID]map[ID]bool
+	// err is the error of the graph as it is, e.g. a negative edge or the deleted source.
+	// The tree is built again after each change until the error is gone.
+	err error
+}
+
+// ShortestPathTree gets the shortest path tree from the source, which subscribes to the changes of the graph until it is closed.
+// Try to get the tree of a graph with negative edges or from a vertex not in the graph will get an error.
+func (graph *Graph) ShortestPathTree(source ID) (*ShortestPathTree, error) {
+	tree := &ShortestPathTree{graph: graph, source: source}
+	if err := tree.Rebuild(); err != nil {
+		return nil, err
+	}
+	graph.subscribe(tree)
+
+	return tree, nil
+}
+
+// Close unsubscribes the tree from the changes of the graph, the tree is not updated any more.
+func (tree *ShortestPathTree) Close() {
+	tree.graph.unsubscribe(tree)
+}
+
+// Rebuild calculates the whole tree again by Dijkstra.
+func (tree *ShortestPathTree) Rebuild() error {
+	dist, prev, err := tree.graph.Dijkstra(tree.source)
+	if err != nil {
+		tree.dist, tree.prev, tree.children, tree.err = nil, nil, nil, err
+		return err
+	}
+
+	tree.dist, tree.prev, tree.err = dist, prev, nil
+	tree.children = make(map[ID]map[ID]bool)
+	for to, from := range prev {
+		if from != nil {
+			tree.link(to, from)
+		}
+	}
+
+	return nil
+}
+
+// Distances gets the copies of the shortest distances and the previous vertices as Dijkstra does.
+// It gets the error if the graph is not valid for the tree any more, e.g. a negative edge is added or the source is deleted.
+func (tree *ShortestPathTree) Distances() (dist map[ID]float64, prev map[ID]ID, err error) {
+	if tree.err != nil {
+		return nil, nil, tree.err
+	}
+
+	dist = make(map[ID]float64, len(tree.dist))
+	for id, each := range tree.dist {
+		dist[id] = each
+	}
+	prev = make(map[ID]ID, len(tree.prev))
+	for id, each := range tree.prev {
+		prev[id] = each
+	}
+
+	return dist, prev, nil
+}
+
+// PathTo gets the shortest path from the source to the destination, +Inf and a nil path if the destination is unreachable.
+// Try to get the path to a vertex not in the graph will get an error.
+func (tree *ShortestPathTree) PathTo(destination ID) (dist float64, path []ID, err error) {
+	if tree.err != nil {
+		return math.Inf(1), nil, tree.err
+	}
+	dist, exists := tree.dist[destination]
+	if !exists {
+		return math.Inf(1), nil, &VertexError{ErrVertexNotFound, destination}
+	}
+	if math.IsInf(dist, 1) {
+		return dist, nil, nil
+	}
+
+	for each := destination; each != nil; each = tree.prev[each] {
+		path = append(path, each)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return dist, path, nil
+}
+
+func (tree *ShortestPathTree) vertexAdded(id ID) {
+	if tree.err != nil {
+		tree.Rebuild()
+		return
+	}
+
+	tree.dist[id] = math.Inf(1)
+	tree.prev[id] = nil
+}
+
+func (tree *ShortestPathTree) vertexDeleted(id ID) {
+	if tree.err != nil || id == tree.source {
+		tree.Rebuild()
+		return
+	}
+
+	affected := tree.subtree(id)
+	delete(affected, id)
+	tree.unlink(id)
+	for child := range tree.children[id] {
+		tree.prev[child] = nil
+	}
+	delete(tree.children, id)
+	delete(tree.dist, id)
+	delete(tree.prev, id)
+	tree.repair(affected)
+}
+
+func (tree *ShortestPathTree) edgeAdded(from, to ID, weight float64) {
+	tree.edgeUpdated(from, to, math.Inf(1), weight)
+}
+
+func (tree *ShortestPathTree) edgeUpdated(from, to ID, old, weight float64) {
+	if tree.err != nil || weight < 0 {
+		tree.Rebuild()
+		return
+	}
+	if _, exists := tree.dist[from]; !exists {
+		return
+	}
+	if _, exists := tree.dist[to]; !exists {
+		return
+	}
+
+	if weight < old {
+		tree.decrease(from, to)
+	} else if weight > old {
+		tree.increase(from, to)
+	}
+}
+
+func (tree *ShortestPathTree) edgeDeleted(from, to ID, weight float64) {
+	if tree.err != nil {
+		tree.Rebuild()
+		return
+	}
+
+	tree.increase(from, to)
+}
+
+// decrease relaxes the shorter edge, and the vertices reached from its head if the distance of its head is decreased.
+func (tree *ShortestPathTree) decrease(from, to ID) {
+	edge := tree.graph.egress[from][to]
+	if !edge.enable || tree.dist[from]+edge.weight >= tree.dist[to] {
+		return
+	}
+
+	tree.dist[to] = tree.dist[from] + edge.weight
+	tree.link(to, from)
+	queue := &treeQueue{}
+	heap.Push(queue, treeEntry{to, tree.dist[to]})
+	tree.propagate(queue)
+}
+
+// increase recalculates the subtree under the edge if the edge is longer or deleted, nothing changes if the edge is not in the tree.
+func (tree *ShortestPathTree) increase(from, to ID) {
+	if prev, exists := tree.prev[to]; !exists || prev != from {
+		return
+	}
+
+	tree.repair(tree.subtree(to))
+}
+
+// repair recalculates the affected vertices, starting from the best edges to them from the vertices not affected.
+func (tree *ShortestPathTree) repair(affected map[ID]bool) {
+	for id := range affected {
+		tree.dist[id] = math.Inf(1)
+		tree.unlink(id)
+	}
+
+	queue := &treeQueue{}
+	for id := range affected {
+		for from, edge := range tree.graph.ingress[id] {
+			if affected[from] || !edge.enable {
+				continue
+			}
+			if dist, exists := tree.dist[from]; exists && dist+edge.weight < tree.dist[id] {
+				tree.dist[id] = dist + edge.weight
+				tree.link(id, from)
+			}
+		}
+		if !math.IsInf(tree.dist[id], 1) {
+			heap.Push(queue, treeEntry{id, tree.dist[id]})
+		}
+	}
+	tree.propagate(queue)
+}
+
+// propagate relaxes the edges from the vertices in the queue as Dijkstra does.
+func (tree *ShortestPathTree) propagate(queue *treeQueue) {
+	for queue.Len() != 0 {
+		min := heap.Pop(queue).(treeEntry)
+		if min.dist > tree.dist[min.id] {
+			continue
+		}
+		for to, edge := range tree.graph.egress[min.id] {
+			if dist, exists := tree.dist[to]; edge.enable && exists && min.dist+edge.weight < dist {
+				tree.dist[to] = min.dist + edge.weight
+				tree.link(to, min.id)
+				heap.Push(queue, treeEntry{to, tree.dist[to]})
+			}
+		}
+	}
+}
+
+// subtree gets the vertex and all the vertices whose shortest paths go through it.
+func (tree *ShortestPathTree) subtree(root ID) map[ID]bool {
+	affected := map[ID]bool{root: true}
+	for queue := []ID{root}; len(queue) != 0; queue = queue[1:] {
+		for child := range tree.children[queue[0]] {
+			affected[child] = true
+			queue = append(queue, child)
+		}
+	}
+
+	return affected
+}
+
+// link sets the previous vertex of the vertex.
+func (tree *ShortestPathTree) link(id, prev ID) {
+	tree.unlink(id)
+	tree.prev[id] = prev
+	if _, exists := tree.children[prev]; !exists {
+		tree.children[prev] = make(map[ID]bool)
+	}
+	tree.children[prev][id] = true
+}
+
+// unlink clears the previous vertex of the vertex.
+func (tree *ShortestPathTree) unlink(id ID) {
+	if prev := tree.prev[id]; prev != nil {
+		delete(tree.children[prev], id)
+	}
+	tree.prev[id] = nil
+}
+
+type treeEntry struct {
+	id   ID
+	dist float64
+}
+
+type treeQueue []treeEntry
+
+func (queue treeQueue) Len() int {
+	return len(queue)
+}
+
+func (queue treeQueue) Less(i, j int) bool {
+	return queue[i].dist < queue[j].dist
+}
+
+func (queue treeQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *treeQueue) Push(x interface{}) {
+	*queue = append(*queue, x.(treeEntry))
+}
+
+func (queue *treeQueue) Pop() interface{} {
+	old := *queue
+	entry := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return entry
+}
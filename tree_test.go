@@ -0,0 +1,190 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+)
+
+var _ = Describe("Tests of ShortestPathTree", func() {
+	var (
+		graph *Graph
+	)
+
+	expectSameAsDijkstra := func(graph *Graph, tree *ShortestPathTree) {
+		expectedDist, _, err := graph.Dijkstra(tree.source)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		dist, prev, err := tree.Distances()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(dist).Should(HaveLen(len(expectedDist)))
+		for id, expected := range expectedDist {
+			if math.IsInf(expected, 1) {
+				Expect(dist[id]).Should(Equal(expected), "%v", id)
+				Expect(prev[id]).Should(BeNil(), "%v", id)
+				continue
+			}
+			Expect(dist[id]).Should(BeNumerically("~", expected, 1e-9), "%v", id)
+			if from := prev[id]; from != nil {
+				Expect(dist[from]+graph.egress[from][id].weight).Should(BeNumerically("~", expected, 1e-9), "%v", id)
+			} else {
+				Expect(id).Should(Equal(tree.source))
+			}
+		}
+	}
+
+	distTo := func(tree *ShortestPathTree, destination ID) float64 {
+		dist, _, err := tree.PathTo(destination)
+		Expect(err).ShouldNot(HaveOccurred())
+		return dist
+	}
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("T", nil)
+			graph.AddEdge("S", "A", 1, nil)
+			graph.AddEdge("A", "T", 1, nil)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when get the tree from non-existed vertex or with negative edge, then get an error.", func() {
+			tree, err := graph.ShortestPathTree("X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+			Expect(tree).Should(BeNil())
+
+			graph.UpdateEdgeWeight("A", "T", -1)
+			_, err = graph.ShortestPathTree("S")
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(graph.listeners).Should(BeEmpty())
+		})
+
+		It("Given a tree, when an edge becomes negative and then non-negative, then get the error until it is fixed.", func() {
+			tree, _ := graph.ShortestPathTree("S")
+
+			graph.UpdateEdgeWeight("A", "T", -1)
+			dist, prev, err := tree.Distances()
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+			Expect(dist).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			_, _, err = tree.PathTo("T")
+			Expect(errors.Is(err, ErrNegativeWeight)).Should(BeTrue())
+
+			graph.UpdateEdgeWeight("A", "T", 2)
+			Expect(distTo(tree, "T")).Should(BeEquivalentTo(3))
+		})
+
+		It("Given a tree, when delete its source, then get ErrVertexNotFound.", func() {
+			tree, _ := graph.ShortestPathTree("S")
+
+			graph.DeleteVertex("S")
+			_, _, err := tree.Distances()
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+
+			graph.AddVertex("S", nil)
+			graph.AddEdge("S", "T", 5, nil)
+			dist, path, err := tree.PathTo("T")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(BeEquivalentTo(5))
+			Expect(path).Should(Equal([]ID{"S", "T"}))
+		})
+
+		It("Given a tree, when get the path to non-existed or unreachable vertex, then get an error or +Inf.", func() {
+			tree, _ := graph.ShortestPathTree("S")
+
+			_, _, err := tree.PathTo("X")
+			Expect(errors.Is(err, ErrVertexNotFound)).Should(BeTrue())
+
+			graph.AddVertex("X", nil)
+			dist, path, err := tree.PathTo("X")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal(math.Inf(1)))
+			Expect(path).Should(BeNil())
+		})
+
+		It("Given a closed tree, when change the graph, then the tree is not updated.", func() {
+			tree, _ := graph.ShortestPathTree("S")
+			tree.Close()
+			Expect(graph.listeners).Should(BeEmpty())
+
+			graph.UpdateEdgeWeight("A", "T", 10)
+			Expect(distTo(tree, "T")).Should(BeEquivalentTo(2))
+			Expect(tree.Rebuild()).Should(Succeed())
+			Expect(distTo(tree, "T")).Should(BeEquivalentTo(11))
+		})
+	})
+
+	Context("algorithem test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(7))
+			graph = NewGraph()
+			for i := 0; i < 100; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 100; i++ {
+				for j := 0; j < 3; j++ {
+					graph.AddEdge(i, random.Intn(100), float64(random.Intn(10)), nil)
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a tree, when the graph keeps changing, then get the same distances as dijkstra after each change.", func() {
+			tree, err := graph.ShortestPathTree(0)
+			Expect(err).ShouldNot(HaveOccurred())
+			another, _ := graph.ShortestPathTree(1)
+			expectSameAsDijkstra(graph, tree)
+
+			random := rand.New(rand.NewSource(8))
+			next := 100
+			for i := 0; i < 300; i++ {
+				from, to := random.Intn(next), random.Intn(next)
+				if _, exists := graph.vertices[from]; !exists || from < 2 {
+					continue
+				}
+				switch random.Intn(10) {
+				case 0, 1, 2:
+					graph.AddEdge(from, to, float64(random.Intn(10)), nil)
+				case 3, 4, 5:
+					for to := range graph.egress[from] {
+						graph.UpdateEdgeWeight(from, to, float64(random.Intn(20)))
+						break
+					}
+				case 6, 7:
+					for to := range graph.egress[from] {
+						graph.DeleteEdge(from, to)
+						break
+					}
+				case 8:
+					graph.DeleteVertex(from)
+				case 9:
+					graph.AddVertex(next, nil)
+					graph.AddEdge(next, from, 1, nil)
+					graph.AddEdge(from, next, 1, nil)
+					next++
+				}
+				expectSameAsDijkstra(graph, tree)
+			}
+			expectSameAsDijkstra(graph, another)
+		})
+
+		It("Given a tree, when add a vertex with edges, then the tree is updated with the edges.", func() {
+			tree, _ := graph.ShortestPathTree(0)
+			graph.AddVertexWithEdges(&myVertex{"V", map[ID]float64{5: 0}, map[ID]float64{0: 0}})
+			expectSameAsDijkstra(graph, tree)
+			Expect(distTo(tree, 5)).Should(BeZero())
+		})
+	})
+})
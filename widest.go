@@ -0,0 +1,80 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	"context"
+	"math"
+)
+
+// WidestPath gets the widest path from one vertex to all other vertices in the graph.
+// The weight of an edge is taken as its capacity and the width of a path is the minimum capacity along it.
+// The width of the source is +Inf and the width of an unreachable vertex is 0.
+// https://en.wikipedia.org/wiki/Widest_path_problem
+func (graph *Graph) WidestPath(source ID) (width map[ID]float64, prev map[ID]ID, err error) {
+	weights, prev, err := graph.DijkstraWith(source, Widest(nil))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toFloatWeights(weights), prev, nil
+}
+
+// ShortestWidestPath gets the shortest path among all the widest paths between two vertices in the graph.
+// The capacity of an edge is given by the input function while its weight is taken as the distance, the capacity is the weight if nil.
+// Shortest-widest paths can not be calculated by Lexicographic algebra, so the widest width is calculated first
+// and then the shortest path is calculated with the narrower edges skipped.
+func (graph *Graph) ShortestWidestPath(source, destination ID, capacity EdgeMetric) (width float64, dist float64, path []ID, err error) {
+	if _, exists := graph.vertices[destination]; !exists {
+		return 0, math.Inf(1), nil, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	widths, _, err := graph.DijkstraWith(source, Widest(capacity))
+	if err != nil {
+		return 0, math.Inf(1), nil, err
+	}
+	width = widths[destination].(float64)
+	if width == 0 {
+		return 0, math.Inf(1), nil, nil
+	}
+
+	compact := graph.compact()
+	blocked := newBlocking()
+	for from, out := range graph.egress {
+		for to, edge := range out {
+			edgeCapacity := edge.weight
+			if capacity != nil {
+				edgeCapacity = capacity(from, to)
+			}
+			if edgeCapacity < width {
+				blocked.blockEdge(from, to)
+			}
+		}
+	}
+	start, target := compact.index[source], compact.index[destination]
+	dists, prev, err := compact.dijkstra(context.Background(), start, target, compact.block(blocked), nil)
+	if err != nil {
+		return 0, math.Inf(1), nil, err
+	}
+
+	return width, dists[target], compact.getPath(prev, target), nil
+}
+
+// WidestShortestPath gets the widest path among all the shortest paths between two vertices in the graph.
+// The capacity of an edge is given by the input function while its weight is taken as the distance, the capacity is the weight if nil.
+func (graph *Graph) WidestShortestPath(source, destination ID, capacity EdgeMetric) (width float64, dist float64, path []ID, err error) {
+	if _, exists := graph.vertices[destination]; !exists {
+		return 0, math.Inf(1), nil, &VertexError{ErrVertexNotFound, destination}
+	}
+
+	weights, prev, err := graph.DijkstraWith(source, Lexicographic(Shortest(nil), Widest(capacity)))
+	if err != nil {
+		return 0, math.Inf(1), nil, err
+	}
+	dist = weights[destination].([]PathWeight)[0].(float64)
+	if dist == math.Inf(1) {
+		return 0, dist, nil, nil
+	}
+
+	return weights[destination].([]PathWeight)[1].(float64), dist, getPath(prev, destination), nil
+}
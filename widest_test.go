@@ -0,0 +1,191 @@
+// Copyright(c) 2016 Ethan Zhuang <zhuangwj@gmail.com>.
+
+package goraph
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+)
+
+var _ = Describe("Tests of WidestPath", func() {
+	var (
+		graph *Graph
+	)
+
+	Context("exception test", func() {
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertexWithEdges(&myVertex{"S", map[ID]float64{"A": 10, "B": 10}, map[ID]float64{}})
+			graph.AddVertexWithEdges(&myVertex{"A", map[ID]float64{}, map[ID]float64{"S": 10, "B": 5}})
+			graph.AddVertexWithEdges(&myVertex{"B", map[ID]float64{"A": 5}, map[ID]float64{"S": 10}})
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph without vertex X, when call widest path api with X, then get two nil and error", func() {
+			width, prev, err := graph.WidestPath("X")
+			Expect(width).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph with negative capacity, when call widest path api, then get two nil and error", func() {
+			graph.UpdateEdgeWeight("B", "A", -5)
+
+			width, prev, err := graph.WidestPath("S")
+			Expect(width).Should(BeNil())
+			Expect(prev).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("Given a graph without vertex X, when call shortest widest path and widest shortest path api with X, then get error", func() {
+			capacity := func(from, to ID) float64 { return 1 }
+			_, _, path, err := graph.ShortestWidestPath("S", "X", capacity)
+			Expect(path).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+			_, _, path, err = graph.WidestShortestPath("X", "S", capacity)
+			Expect(path).Should(BeNil())
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("algorithem test", func() {
+		var (
+			capacities map[ID]map[ID]float64
+			capacity   func(from, to ID) float64
+		)
+
+		BeforeEach(func() {
+			graph = NewGraph()
+			graph.AddVertex("S", nil)
+			graph.AddVertex("A", nil)
+			graph.AddVertex("B", nil)
+			graph.AddVertex("C", nil)
+			graph.AddVertex("T", nil)
+			graph.AddVertex("X", nil)
+			graph.AddEdge("S", "A", 1, nil)
+			graph.AddEdge("A", "T", 1, nil)
+			graph.AddEdge("S", "B", 2, nil)
+			graph.AddEdge("B", "T", 2, nil)
+			graph.AddEdge("S", "C", 3, nil)
+			graph.AddEdge("C", "T", 3, nil)
+			graph.AddEdge("A", "B", 1, nil)
+			capacities = map[ID]map[ID]float64{
+				"S": {"A": 5, "B": 10, "C": 10},
+				"A": {"T": 5, "B": 10},
+				"B": {"T": 2},
+				"C": {"T": 5},
+			}
+			capacity = func(from, to ID) float64 {
+				return capacities[from][to]
+			}
+			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call widest path api with source vertex, then get the widest paths from the source vertices to all other vertex in the graph.", func() {
+			graph.UpdateEdgeWeight("A", "T", math.Inf(1))
+			expectedWidth := map[ID]float64{
+				"S": math.Inf(1),
+				"A": 1,
+				"B": 2,
+				"C": 3,
+				"T": 3,
+				"X": 0,
+			}
+			expectedPrev := map[ID]ID{
+				"S": nil,
+				"A": "S",
+				"B": "S",
+				"C": "S",
+				"T": "C",
+				"X": nil,
+			}
+
+			width, prev, err := graph.WidestPath("S")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(expectedWidth))
+			Expect(prev).Should(BeEquivalentTo(expectedPrev))
+		})
+
+		It("Given a graph with several widest paths, when call shortest widest path api, then get the shortest one among them.", func() {
+			width, dist, path, err := graph.ShortestWidestPath("S", "T", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(5))
+			Expect(dist).Should(BeEquivalentTo(2))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "A", "T"}))
+
+			capacities["A"]["T"] = 1
+			width, dist, path, err = graph.ShortestWidestPath("S", "T", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(5))
+			Expect(dist).Should(BeEquivalentTo(6))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "C", "T"}))
+		})
+
+		It("Given a graph with several shortest paths, when call widest shortest path api, then get the widest one among them.", func() {
+			graph.UpdateEdgeWeight("C", "T", 1)
+			width, dist, path, err := graph.WidestShortestPath("S", "T", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(5))
+			Expect(dist).Should(BeEquivalentTo(2))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "A", "T"}))
+
+			graph.UpdateEdgeWeight("S", "C", 1)
+			capacities["C"]["T"] = 10
+			width, dist, path, err = graph.WidestShortestPath("S", "T", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(10))
+			Expect(dist).Should(BeEquivalentTo(2))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "C", "T"}))
+		})
+
+		It("Given a graph, when call shortest widest path and widest shortest path api with unreachable vertex, then get no path.", func() {
+			width, dist, path, err := graph.ShortestWidestPath("S", "X", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(0))
+			Expect(dist).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(path).Should(BeNil())
+
+			width, dist, path, err = graph.WidestShortestPath("S", "X", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(0))
+			Expect(dist).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(path).Should(BeNil())
+		})
+
+		It("Given a graph with some edges disabled, when call shortest widest path api, then the disabled edges are skipped and kept disabled.", func() {
+			graph.DisableEdge("A", "T")
+			width, dist, path, err := graph.ShortestWidestPath("S", "T", capacity)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(5))
+			Expect(dist).Should(BeEquivalentTo(6))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "C", "T"}))
+			for from, out := range graph.egress {
+				for to, edge := range out {
+					Expect(edge.enable).Should(Equal(from != "A" || to != "T"))
+				}
+			}
+		})
+
+		It("Given a graph, when call shortest widest path and widest shortest path api without capacity, then the weights are taken as the capacities.", func() {
+			width, dist, path, err := graph.ShortestWidestPath("S", "T", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(3))
+			Expect(dist).Should(BeEquivalentTo(6))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "C", "T"}))
+
+			width, dist, path, err = graph.WidestShortestPath("S", "T", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(width).Should(BeEquivalentTo(1))
+			Expect(dist).Should(BeEquivalentTo(2))
+			Expect(path).Should(BeEquivalentTo([]ID{"S", "A", "T"}))
+		})
+	})
+})
@@ -3,93 +3,441 @@
 package goraph
 
 import (
-	"math"
+	"container/heap"
+	"context"
 	"sort"
+	"sync"
 )
 
 type potential struct {
-	dist float64
-	path []ID
+	dist      PathWeight
+	path      []ID
+	deviation int
+	sequence  int
 }
 
 // Yen gets top k shortest loopless path between two vertex in the graph.
 // https://en.wikipedia.org/wiki/Yen%27s_algorithm
 func (graph *Graph) Yen(source, destination ID, topK int) ([]float64, [][]ID, error) {
-	var err error
-	var i, j, k int
-	var dijkstraDist map[ID]float64
-	var dijkstraPrev map[ID]ID
-	var existed bool
-	var spurWeight float64
-	var spurPath []ID
-	var potentials []potential
+	return graph.YenCtx(context.Background(), source, destination, topK)
+}
+
+// YenCtx gets top k shortest loopless path between two vertex in the graph, and stops when the context is done.
+// If the context is done, the paths found so far are returned together with the error of the context.
+func (graph *Graph) YenCtx(ctx context.Context, source, destination ID, topK int) ([]float64, [][]ID, error) {
+	weights, pathTopK, err := graph.yenWith(ctx, source, destination, topK, Shortest(nil))
+	if weights == nil {
+		return nil, nil, err
+	}
+
 	distTopK := make([]float64, topK)
+	for i, weight := range weights {
+		distTopK[i] = weight.(float64)
+	}
+
+	return distTopK, pathTopK, err
+}
+
+// YenWith gets top k best loopless path under the algebra between two vertex in the graph.
+// The weight of a missing path is the zero of the algebra.
+func (graph *Graph) YenWith(source, destination ID, topK int, algebra PathAlgebra) ([]PathWeight, [][]ID, error) {
+	return graph.yenWith(context.Background(), source, destination, topK, algebra)
+}
+
+func (graph *Graph) yenWith(ctx context.Context, source, destination ID, topK int, algebra PathAlgebra) ([]PathWeight, [][]ID, error) {
+	distTopK := make([]PathWeight, topK)
 	pathTopK := make([][]ID, topK)
 	for i := 0; i < topK; i++ {
-		distTopK[i] = math.Inf(1)
+		distTopK[i] = algebra.Zero()
+	}
+
+	search := graph.newYenSearch(ctx, source, destination, algebra)
+	for k := 0; k < topK; k++ {
+		found, err := search.next()
+		if found != nil {
+			distTopK[k] = found.dist
+			pathTopK[k] = found.path
+		}
+		if err != nil {
+			if k == 0 && found == nil {
+				return nil, nil, err
+			}
+			return distTopK, pathTopK, err
+		}
+		if found == nil {
+			break
+		}
+	}
+
+	return distTopK, pathTopK, nil
+}
+
+// Path is a path between two vertices in the graph together with its weight.
+type Path struct {
+	Weight   float64
+	Vertices []ID
+}
+
+// YenIterator yields the shortest loopless paths between two vertices one after another.
+type YenIterator struct {
+	search *yenSearch
+	err    error
+}
+
+// YenIter gets an iterator of the shortest loopless paths between two vertex in the graph, in nondecreasing order of their weights.
+// The paths are calculated on demand, so the iteration can stop at any time without calculating the rest.
+// The only path from a vertex to itself is nil with weight 0, the same as the first path Yen gets.
+func (graph *Graph) YenIter(source, destination ID) *YenIterator {
+	iter := &YenIterator{search: graph.newYenSearch(context.Background(), source, destination, Shortest(nil))}
+	if _, exists := graph.vertices[destination]; !exists {
+		iter.err = &VertexError{ErrVertexNotFound, destination}
+	}
+
+	return iter
+}
+
+// Next gets the next shortest loopless path, it gets false if there is no more path or an error occurs.
+// A path found before the error is still returned, and the iteration stops at the next call.
+func (iter *YenIterator) Next() (Path, bool) {
+	if iter.err != nil {
+		return Path{}, false
+	}
+
+	found, err := iter.search.next()
+	iter.err = err
+	if found == nil {
+		return Path{}, false
+	}
+
+	return Path{found.dist.(float64), found.path}, true
+}
+
+// Err gets the error stopped the iteration, nil if the iteration stopped because there is no more path.
+func (iter *YenIterator) Err() error {
+	return iter.err
+}
+
+// yenSearch keeps the state of Yen between two paths found.
+type yenSearch struct {
+	graph       *Graph
+	ctx         context.Context
+	source      ID
+	destination ID
+	algebra     PathAlgebra
+	found       *pathTrie
+	seen        *pathTrie
+	potentials  *potentialHeap
+	last        *potential
+	exhausted   bool
+	// spurred reports whether the spur paths of the last found path are pushed.
+	spurred bool
+	// ready is the paths found with the same weight to return in order in the deterministic mode.
+	ready []*potential
+	// compact is the compact snapshot to calculate the paths on, nil if the algebra needs the original graph.
+	compact *compactGraph
+}
+
+func (graph *Graph) newYenSearch(ctx context.Context, source, destination ID, algebra PathAlgebra) *yenSearch {
+	search := &yenSearch{
+		graph:       graph,
+		ctx:         ctx,
+		source:      source,
+		destination: destination,
+		algebra:     algebra,
+		found:       newPathTrie(),
+		seen:        newPathTrie(),
+		potentials:  newPotentialHeap(graph, algebra),
+	}
+	if isCompactable(algebra) {
+		search.compact = graph.compact()
+	}
+
+	return search
+}
+
+// next gets the next best loopless path, nil if there is no more path.
+// In the deterministic mode, all the paths with equal weights within the tolerance are found before the first of them is returned,
+// so that they are returned in order of their paths, since a path found later by a spur may be ordered before the ones found earlier.
+// If a spur fails before all of them are found, the first of the ones found is returned together with the error.
+func (search *yenSearch) next() (*potential, error) {
+	if len(search.ready) != 0 {
+		found := search.ready[0]
+		search.ready = search.ready[1:]
+		return found, nil
+	}
+
+	found, err := search.pop()
+	if err != nil || found == nil || !search.graph.deterministic {
+		return found, err
+	}
+
+	group := []*potential{found}
+	for {
+		if err = search.spur(); err != nil {
+			break
+		}
+		if search.potentials.Len() == 0 || !search.equalWeights(found.dist, search.potentials.items[0].dist) {
+			break
+		}
+		search.accept(heap.Pop(search.potentials).(*potential))
+		group = append(group, search.last)
+	}
+	sort.SliceStable(group, func(i, j int) bool {
+		return search.graph.pathBefore(group[i].path, group[j].path)
+	})
+	search.ready = group[1:]
+
+	return group[0], err
+}
+
+// equalWeights reports whether the two weights are equal under the algebra, within the tolerance of the graph if they are numbers.
+func (search *yenSearch) equalWeights(a, b PathWeight) bool {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			return search.graph.equalWeights(x, y)
+		}
+	}
+
+	return !search.algebra.Better(a, b) && !search.algebra.Better(b, a)
+}
+
+// pop gets the best path not found yet and marks it found, nil if there is no more path.
+func (search *yenSearch) pop() (*potential, error) {
+	if search.exhausted {
+		return nil, nil
+	}
+
+	if search.last == nil {
+		dist, path, err := search.bestPath(search.source, nil)
+		if err != nil {
+			return nil, err
+		}
+		// the path from a vertex to itself is nil with the identity weight, as Yen gets it since the first version.
+		if path == nil && search.source != search.destination {
+			search.exhausted = true
+			return nil, nil
+		}
+		search.accept(&potential{dist, path, 0, 0})
+	} else {
+		if err := search.spur(); err != nil {
+			return nil, err
+		}
+		if search.potentials.Len() == 0 {
+			search.exhausted = true
+			return nil, nil
+		}
+		search.accept(heap.Pop(search.potentials).(*potential))
+	}
+
+	return search.last, nil
+}
+
+// accept marks the path found as the last one to spur.
+func (search *yenSearch) accept(found *potential) {
+	search.last = found
+	search.spurred = false
+	search.found.insert(found.path)
+	search.seen.insert(found.path)
+}
+
+// spur pushes the spur paths of the last found path as the candidates, if they are not pushed yet.
+func (search *yenSearch) spur() error {
+	if search.spurred {
+		return nil
+	}
+
+	spurs, err := search.spurPaths()
+	if err != nil {
+		return err
+	}
+	for _, spur := range spurs {
+		if spur != nil && search.seen.insert(spur.path) {
+			heap.Push(search.potentials, spur)
+		}
 	}
+	search.spurred = true
 
-	dijkstraDist, dijkstraPrev, err = graph.Dijkstra(source)
+	return nil
+}
+
+// bestPath gets the best path from the vertex to the destination with the blocked vertices and edges skipped,
+// nil if the destination is unreachable.
+func (search *yenSearch) bestPath(from ID, blocked *blocking) (PathWeight, []ID, error) {
+	if search.compact == nil {
+		dist, prev, err := search.graph.dijkstraWith(search.ctx, from, search.algebra, blocked)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dist[search.destination], getPath(prev, search.destination), nil
+	}
+
+	source, err := search.compact.vertexIndex(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	destination := search.compact.index[search.destination]
+	dist, prev, err := search.compact.dijkstra(search.ctx, source, destination, search.compact.block(blocked), nil)
 	if err != nil {
 		return nil, nil, err
 	}
-	distTopK[0] = dijkstraDist[destination]
-	pathTopK[0] = getPath(dijkstraPrev, destination)
 
-	for k = 1; k < topK; {
-		for i = 0; i < len(pathTopK[k-1])-1; i++ {
-			for j = 0; j < k; j++ {
-				if isShareRootPath(pathTopK[j], pathTopK[k-1][:i+1]) {
-					graph.DisableEdge(pathTopK[j][i], pathTopK[j][i+1])
-				}
-			}
-			graph.DisablePath(pathTopK[k-1][:i])
-
-			dijkstraDist, dijkstraPrev, _ = graph.Dijkstra(pathTopK[k-1][i])
-			if dijkstraDist[destination] != math.Inf(1) {
-				spurWeight = graph.GetPathWeight(pathTopK[k-1][:i+1]) + dijkstraDist[destination]
-				spurPath = mergePath(pathTopK[k-1][:i], getPath(dijkstraPrev, destination))
-				existed = false
-				for _, each := range potentials {
-					if isSamePath(each.path, spurPath) {
-						existed = true
-						break
-					}
-				}
-				if !existed {
-					potentials = append(potentials, potential{
-						spurWeight,
-						spurPath,
-					})
+	return dist[destination], search.compact.getPath(prev, destination), nil
+}
+
+// spurPaths gets the spur path from each vertex of the last found path, nil if there is no spur path from the vertex.
+// Following Lawler, only the vertices from the deviation of the last found path are spurred,
+// since the spur paths from the vertices before it have been found when its parent path was spurred.
+// The spur paths are independent and calculated by the workers of the graph in parallel.
+func (search *yenSearch) spurPaths() ([]*potential, error) {
+	last := search.last
+	if len(last.path)-1 <= last.deviation {
+		return nil, nil
+	}
+
+	spurs := make([]*potential, len(last.path)-1-last.deviation)
+	errs := make([]error, len(spurs))
+	spur := func(index int) {
+		i := last.deviation + index
+		blocked := newBlocking()
+		for next := range search.found.find(last.path[:i+1]).children {
+			blocked.blockEdge(last.path[i], next)
+		}
+		for _, vertex := range last.path[:i] {
+			blocked.blockVertex(vertex)
+		}
+
+		_, path, err := search.bestPath(last.path[i], blocked)
+		if err != nil {
+			errs[index] = err
+			return
+		}
+		if path != nil {
+			path = mergePath(last.path[:i], path)
+			weight, _ := search.graph.GetPathWeightWith(path, search.algebra)
+			spurs[index] = &potential{weight, path, i, 0}
+		}
+	}
+
+	if search.graph.workers <= 1 {
+		for index := range spurs {
+			spur(index)
+		}
+	} else {
+		var wg sync.WaitGroup
+		indices := make(chan int)
+		for w := 0; w < search.graph.workers && w < len(spurs); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for index := range indices {
+					spur(index)
 				}
-			}
+			}()
+		}
+		for index := range spurs {
+			indices <- index
+		}
+		close(indices)
+		wg.Wait()
+	}
 
-			graph.Reset()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		if len(potentials) == 0 {
-			break
+	return spurs, nil
+}
+
+// potentialHeap is the candidate paths of Yen ordered by their weights under the algebra.
+// Candidates with equal weights are ordered by their paths in the deterministic mode, otherwise by the order they are pushed.
+type potentialHeap struct {
+	graph    *Graph
+	algebra  PathAlgebra
+	items    []*potential
+	sequence int
+}
+
+func newPotentialHeap(graph *Graph, algebra PathAlgebra) *potentialHeap {
+	return &potentialHeap{graph: graph, algebra: algebra}
+}
+
+func (potentials *potentialHeap) Len() int {
+	return len(potentials.items)
+}
+
+func (potentials *potentialHeap) Less(i, j int) bool {
+	a, b := potentials.items[i], potentials.items[j]
+	if potentials.algebra.Better(a.dist, b.dist) {
+		return true
+	}
+	if potentials.algebra.Better(b.dist, a.dist) {
+		return false
+	}
+	if potentials.graph.deterministic && !isSamePath(a.path, b.path) {
+		return potentials.graph.pathBefore(a.path, b.path)
+	}
+	return a.sequence < b.sequence
+}
+
+func (potentials *potentialHeap) Swap(i, j int) {
+	potentials.items[i], potentials.items[j] = potentials.items[j], potentials.items[i]
+}
+
+func (potentials *potentialHeap) Push(x interface{}) {
+	potentials.sequence++
+	x.(*potential).sequence = potentials.sequence
+	potentials.items = append(potentials.items, x.(*potential))
+}
+
+func (potentials *potentialHeap) Pop() interface{} {
+	item := potentials.items[len(potentials.items)-1]
+	potentials.items = potentials.items[:len(potentials.items)-1]
+	return item
+}
+
+// pathTrie is a prefix tree of paths, in which the children of each node are hashed by their ids.
+type pathTrie struct {
+	children map[ID]*pathTrie
+	end      bool
+}
+
+func newPathTrie() *pathTrie {
+	return &pathTrie{children: make(map[ID]*pathTrie)}
+}
+
+// insert adds the path into the trie, it gets false if the path is already in the trie.
+func (trie *pathTrie) insert(path []ID) bool {
+	node := trie
+	for _, vertex := range path {
+		child, exists := node.children[vertex]
+		if !exists {
+			child = newPathTrie()
+			node.children[vertex] = child
 		}
-		sort.Slice(potentials, func(i, j int) bool {
-			return potentials[i].dist < potentials[j].dist
-		})
+		node = child
+	}
+	if node.end {
+		return false
+	}
+	node.end = true
 
-		if len(potentials) >= topK-k {
-			for l := 0; k < topK; l++ {
-				distTopK[k] = potentials[l].dist
-				pathTopK[k] = potentials[l].path
-				k++
-			}
-			break
-		} else {
-			distTopK[k] = potentials[0].dist
-			pathTopK[k] = potentials[0].path
-			potentials = potentials[1:]
-			k++
+	return true
+}
+
+// find gets the node of the prefix in the trie, an empty node if no path in the trie has the prefix.
+func (trie *pathTrie) find(prefix []ID) *pathTrie {
+	node := trie
+	for _, vertex := range prefix {
+		child, exists := node.children[vertex]
+		if !exists {
+			return &pathTrie{}
 		}
+		node = child
 	}
 
-	return distTopK, pathTopK, nil
+	return node
 }
 
 func isShareRootPath(path, rootPath []ID) bool {
@@ -3,9 +3,13 @@
 package goraph
 
 import (
+	"context"
+	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"math"
+	"math/rand"
+	"sort"
 )
 
 var _ = Describe("Tests of Yen", func() {
@@ -36,6 +40,18 @@ var _ = Describe("Tests of Yen", func() {
 			Expect(path).Should(BeNil())
 		})
 
+		It("Given a context done during the calculation, when call yen api with the context, then get the paths found so far and the error of the context.", func() {
+			dist, path, err := graph.YenCtx(&countdownContext{context.Background(), 1}, "C", "H", 3)
+			Expect(err).Should(Equal(context.Canceled))
+			Expect(dist).Should(BeEquivalentTo([]float64{5, math.Inf(1), math.Inf(1)}))
+			Expect(path).Should(BeEquivalentTo([][]ID{{"C", "E", "F", "H"}, nil, nil}))
+			for _, out := range graph.egress {
+				for _, edge := range out {
+					Expect(edge.enable).Should(BeTrue())
+				}
+			}
+		})
+
 		It("Given a graph with negative edge, when call yen api, then get error.", func() {
 			graph.AddEdge("F", "E", -1, nil)
 			Expect(graph.CheckIntegrity()).ShouldNot(HaveOccurred())
@@ -114,6 +130,62 @@ var _ = Describe("Tests of Yen", func() {
 			Expect(dist[2]).Should(BeEquivalentTo(3))
 			Expect(path[2]).Should(BeEquivalentTo([]ID{"0", "2", "3", "4"}))
 		})
+
+		It("Given a graph, when iterate with yen iterator, then get the same paths as yen one after another until no more path.", func() {
+			expectedDist, expectedPath, _ := graph.Yen("C", "H", 7)
+
+			iter := graph.YenIter("C", "H")
+			for i := 0; i < 7; i++ {
+				path, ok := iter.Next()
+				Expect(ok).Should(BeTrue())
+				Expect(path.Weight).Should(BeEquivalentTo(expectedDist[i]))
+				Expect(path.Vertices).Should(BeEquivalentTo(expectedPath[i]))
+			}
+			_, ok := iter.Next()
+			Expect(ok).Should(BeFalse())
+			_, ok = iter.Next()
+			Expect(ok).Should(BeFalse())
+			Expect(iter.Err()).ShouldNot(HaveOccurred())
+		})
+
+		It("Given a graph, when iterate with yen iterator until a path satisfies a check, then stop without calculating the rest.", func() {
+			iter := graph.YenIter("C", "H")
+			var path Path
+			for ok := true; ok; {
+				if path, ok = iter.Next(); ok && len(path.Vertices) == 5 {
+					break
+				}
+			}
+			Expect(path.Weight).Should(BeEquivalentTo(8))
+			Expect(path.Vertices).Should(BeEquivalentTo([]ID{"C", "E", "F", "G", "H"}))
+		})
+
+		It("Given a graph, when iterate with yen iterator on non-existed vertex or negative edge, then stop with error.", func() {
+			iter := graph.YenIter("C", "X")
+			_, ok := iter.Next()
+			Expect(ok).Should(BeFalse())
+			Expect(iter.Err()).Should(MatchError(ErrVertexNotFound))
+
+			graph.AddEdge("F", "E", -1, nil)
+			iter = graph.YenIter("C", "H")
+			_, ok = iter.Next()
+			Expect(ok).Should(BeFalse())
+			Expect(iter.Err()).Should(MatchError(ErrNegativeWeight))
+		})
+
+		It("Given the source is the destination, when iterate with yen iterator, then get the nil path with weight 0 only as yen does.", func() {
+			iter := graph.YenIter("C", "C")
+			path, ok := iter.Next()
+			Expect(ok).Should(BeTrue())
+			Expect(path).Should(Equal(Path{0, nil}))
+			_, ok = iter.Next()
+			Expect(ok).Should(BeFalse())
+
+			dist, paths, err := graph.Yen("C", "C", 3)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal([]float64{0, math.Inf(1), math.Inf(1)}))
+			Expect(paths).Should(Equal([][]ID{nil, nil, nil}))
+		})
 	})
 
 	Context("bugfix test", func() {
@@ -148,5 +220,224 @@ var _ = Describe("Tests of Yen", func() {
 			Expect(dist[3]).Should(BeEquivalentTo(4))
 			Expect(path[3]).Should(BeEquivalentTo([]ID{"0", "1", "2", "3", "4"}))
 		})
+
+		It("Given a graph with equal weight paths in the deterministic mode, when call yen api, then the paths are in lexicographic order of the vertices.", func() {
+			for i := 0; i < 6; i++ {
+				graph.AddVertex(i, nil)
+			}
+			graph.AddEdge(0, 5, 3, nil)
+			graph.AddEdge(0, 1, 1, nil)
+			graph.AddEdge(1, 5, 2, nil)
+			graph.AddEdge(1, 2, 1, nil)
+			graph.AddEdge(2, 5, 1, nil)
+			graph.AddEdge(0, 2, 2, nil)
+			graph.SetDeterministic(true)
+
+			dist, path, err := graph.Yen(0, 5, 4)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist).Should(Equal([]float64{3, 3, 3, 3}))
+			Expect(path).Should(Equal([][]ID{{0, 1, 2, 5}, {0, 1, 5}, {0, 2, 5}, {0, 5}}))
+
+			iter := graph.YenIter(0, 5)
+			for _, expected := range path {
+				found, ok := iter.Next()
+				Expect(ok).Should(BeTrue())
+				Expect(found.Vertices).Should(Equal(expected))
+			}
+		})
+
+		It("Given a graph with paths of equal weights within the tolerance in the deterministic mode, when call yen api, then the paths are in lexicographic order of the vertices.", func() {
+			for i := 0; i < 3; i++ {
+				graph.AddVertex(i, nil)
+			}
+			graph.AddEdge(0, 2, 0.3, nil)
+			graph.AddEdge(0, 1, 0.1, nil)
+			graph.AddEdge(1, 2, 0.2, nil)
+			graph.SetDeterministic(true)
+
+			dist, path, err := graph.Yen(0, 2, 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist[0]).Should(BeNumerically("~", 0.3, 1e-9))
+			Expect(dist[1]).Should(BeNumerically("~", 0.3, 1e-9))
+			Expect(path).Should(Equal([][]ID{{0, 1, 2}, {0, 2}}))
+		})
+
+		It("Given a context done while the paths with equal weights are found in the deterministic mode, when call yen api with the context, then get the path found together with the error.", func() {
+			for i := 0; i < 6; i++ {
+				graph.AddVertex(i, nil)
+			}
+			graph.AddEdge(0, 5, 3, nil)
+			graph.AddEdge(0, 1, 1, nil)
+			graph.AddEdge(1, 5, 2, nil)
+			graph.AddEdge(1, 2, 1, nil)
+			graph.AddEdge(2, 5, 1, nil)
+			graph.AddEdge(0, 2, 2, nil)
+			graph.SetDeterministic(true)
+
+			dist, path, err := graph.YenCtx(&countdownContext{context.Background(), 1}, 0, 5, 4)
+			Expect(err).Should(Equal(context.Canceled))
+			Expect(dist).Should(Equal([]float64{3, math.Inf(1), math.Inf(1), math.Inf(1)}))
+			Expect(path).Should(Equal([][]ID{{0, 5}, nil, nil, nil}))
+
+			iter := graph.YenIter(0, 5)
+			iter.search.ctx = &countdownContext{context.Background(), 1}
+			found, ok := iter.Next()
+			Expect(ok).Should(BeTrue())
+			Expect(found).Should(Equal(Path{3, []ID{0, 5}}))
+			Expect(iter.Err()).Should(Equal(context.Canceled))
+			_, ok = iter.Next()
+			Expect(ok).Should(BeFalse())
+		})
+	})
+
+	Context("parallel test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(1))
+			graph = NewGraph()
+			for i := 0; i < 100; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 100; i++ {
+				for j := 0; j < 5; j++ {
+					graph.AddEdge(i, random.Intn(100), float64(random.Intn(10)), nil)
+				}
+			}
+			graph.SetDeterministic(true)
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph with several workers, when call yen api, then get the same paths as the sequential calculation.", func() {
+			expectedDist, expectedPath, err := graph.Yen(0, 99, 20)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			for _, workers := range []int{2, 4, 16} {
+				graph.SetWorkers(workers)
+				dist, path, err := graph.Yen(0, 99, 20)
+				Expect(err).ShouldNot(HaveOccurred(), fmt.Sprintf("workers: %d", workers))
+				Expect(dist).Should(BeEquivalentTo(expectedDist), fmt.Sprintf("workers: %d", workers))
+				Expect(path).Should(BeEquivalentTo(expectedPath), fmt.Sprintf("workers: %d", workers))
+			}
+		})
+
+		It("Given a graph with some edges disabled, when call yen api, then the disabled edges are neither calculated nor enabled.", func() {
+			_, expectedPath, _ := graph.Yen(0, 99, 1)
+			graph.DisableEdge(expectedPath[0][0], expectedPath[0][1])
+			graph.SetWorkers(4)
+
+			_, path, err := graph.Yen(0, 99, 10)
+			Expect(err).ShouldNot(HaveOccurred())
+			for _, each := range path {
+				Expect(isShareRootPath(each, expectedPath[0][:2])).Should(BeFalse())
+			}
+			Expect(graph.egress[expectedPath[0][0]][expectedPath[0][1]].enable).Should(BeFalse())
+		})
+
+		It("Given a graph, when call yen api with k in the thousands, then get unique loopless paths in nondecreasing order.", func() {
+			dist, path, err := graph.Yen(0, 99, 2000)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			seen := make(map[string]bool)
+			for i := range path {
+				Expect(path[i]).ShouldNot(BeNil())
+				if i > 0 {
+					Expect(dist[i]).Should(BeNumerically(">=", dist[i-1]))
+				}
+				Expect(graph.GetPathWeight(path[i])).Should(BeEquivalentTo(dist[i]))
+
+				vertices := make(map[ID]bool)
+				for _, vertex := range path[i] {
+					Expect(vertices[vertex]).Should(BeFalse())
+					vertices[vertex] = true
+				}
+				Expect(seen[fmt.Sprint(path[i])]).Should(BeFalse())
+				seen[fmt.Sprint(path[i])] = true
+			}
+		})
+	})
+
+	Context("exhaustive test", func() {
+		BeforeEach(func() {
+			random := rand.New(rand.NewSource(2))
+			graph = NewGraph()
+			for i := 0; i < 8; i++ {
+				graph.AddVertex(i, nil)
+			}
+			for i := 0; i < 8; i++ {
+				for j := 0; j < 8; j++ {
+					if i != j && random.Intn(2) == 0 {
+						graph.AddEdge(i, j, float64(random.Intn(5)), nil)
+					}
+				}
+			}
+		})
+
+		AfterEach(func() {
+			graph = nil
+		})
+
+		It("Given a graph, when call yen api with k larger than the number of paths, then get the weights of all the loopless paths.", func() {
+			var expectedDist []float64
+			visited := make(map[ID]bool)
+			var walk func(vertex ID, weight float64)
+			walk = func(vertex ID, weight float64) {
+				if vertex == 7 {
+					expectedDist = append(expectedDist, weight)
+					return
+				}
+				visited[vertex] = true
+				for to, edge := range graph.egress[vertex] {
+					if !visited[to] {
+						walk(to, weight+edge.weight)
+					}
+				}
+				visited[vertex] = false
+			}
+			walk(0, 0)
+			sort.Float64s(expectedDist)
+
+			dist, path, err := graph.Yen(0, 7, len(expectedDist)+1)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(dist[:len(expectedDist)]).Should(BeEquivalentTo(expectedDist))
+			Expect(dist[len(expectedDist)]).Should(BeEquivalentTo(math.Inf(1)))
+			Expect(path[len(expectedDist)]).Should(BeNil())
+		})
+
+		It("Given a graph in the deterministic mode, when call yen api, then the paths with equal weights are in lexicographic order.", func() {
+			graph.SetDeterministic(true)
+			var expected []Path
+			visited := make(map[ID]bool)
+			var walk func(path []ID, weight float64)
+			walk = func(path []ID, weight float64) {
+				vertex := path[len(path)-1]
+				if vertex == 7 {
+					expected = append(expected, Path{weight, append([]ID{}, path...)})
+					return
+				}
+				visited[vertex] = true
+				for to, edge := range graph.egress[vertex] {
+					if !visited[to] {
+						walk(append(path, to), weight+edge.weight)
+					}
+				}
+				visited[vertex] = false
+			}
+			walk([]ID{0}, 0)
+			sort.Slice(expected, func(i, j int) bool {
+				if expected[i].Weight != expected[j].Weight {
+					return expected[i].Weight < expected[j].Weight
+				}
+				return graph.pathBefore(expected[i].Vertices, expected[j].Vertices)
+			})
+
+			dist, path, err := graph.Yen(0, 7, len(expected))
+			Expect(err).ShouldNot(HaveOccurred())
+			for i := range expected {
+				Expect(dist[i]).Should(Equal(expected[i].Weight))
+				Expect(path[i]).Should(Equal(expected[i].Vertices), "path %d", i)
+			}
+		})
 	})
 })